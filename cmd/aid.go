@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"pickit/internal/utils"
+	"strconv"
+)
+
+// aidValue 车牌号标志，接受数字、JM 编号和本子/章节链接
+type aidValue struct {
+	ref utils.AlbumRef
+}
+
+func (v *aidValue) String() string {
+	if v.ref.Id == 0 {
+		return ""
+	}
+	return strconv.Itoa(v.ref.Id)
+}
+
+func (v *aidValue) Set(s string) error {
+	ref, err := utils.ParseAlbumRef(s)
+	if err != nil {
+		return err
+	}
+	v.ref = ref
+	return nil
+}
+
+func (v *aidValue) Type() string {
+	return "aid"
+}
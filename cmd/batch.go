@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"pickit/internal/mode"
+	"pickit/internal/utils"
+	"text/tabwriter"
+)
+
+type batchFlags struct {
+	concurrency int    // 并发数
+	proxy       string // 魔法
+}
+
+var batchOpts batchFlags
+
+var batchCmd = &cobra.Command{
+	Use:   "batch <任务文件>",
+	Short: "按任务文件批量下载、还原、合成 PDF",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		file, err := utils.LoadBatchFile(args[0])
+		if err != nil {
+			fatalUsage("读取批量任务失败", utils.Err(err))
+		}
+
+		// 命令行参数优先于任务文件
+		concurrency := file.Concurrency
+		if userFlag(cmd, "concurrency") || concurrency <= 0 {
+			concurrency = batchOpts.concurrency
+		}
+		proxy := file.Proxy
+		if userFlag(cmd, "proxy") {
+			proxy = batchOpts.proxy
+		}
+
+		if dryRun {
+			reportPlan(mode.PlanBatch(cmd.Context(), file, utils.ReadJobStore(jobDir), newMetadataProvider(proxy), concurrency, newHTTPClient(proxy)))
+			return
+		}
+
+		results := mode.RunBatch(cmd.Context(), file, openJobStore(), newMetadataProvider(proxy), concurrency, newHTTPClient(proxy))
+		addBatchItems(results)
+		if !jsonOutput {
+			printBatchSummary(results)
+		}
+	},
+}
+
+// printBatchSummary 输出批量任务汇总
+func printBatchSummary(results []mode.BatchJobResult) {
+	counts := make(map[string]int)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, utils.T("车牌号\t状态\t导出\t页数\t失败\t输出目录\t错误"))
+	for _, r := range results {
+		counts[r.Status()]++
+		errMsg := ""
+		if r.Err != nil {
+			errMsg = r.Err.Error()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\t%s\n", r.Input, r.Status(), r.Export, r.Pages, r.Failed, r.Output, errMsg)
+	}
+	_ = w.Flush()
+	fmt.Printf(utils.T("共 %d 个本子，成功 %d，部分失败 %d，失败 %d\n"), len(results), counts["ok"], counts["partial"], counts["failed"])
+}
+
+func init() {
+	rootCmd.AddCommand(batchCmd)
+
+	// 本地标志
+	batchCmd.Flags().IntVarP(&batchOpts.concurrency, "concurrency", "c", 8, "所有本子共用的并发数，优先于任务文件（可选）")
+	batchCmd.Flags().StringVarP(&batchOpts.proxy, "proxy", "p", "", "魔法，优先于任务文件（可选）")
+	batchCmd.Flags().StringVar(&jobDir, "jobs", utils.DefaultJobDir(), "任务目录，中断后再次执行同一任务文件会从上次的进度继续（可选）")
+	addDryRunFlag(batchCmd)
+}
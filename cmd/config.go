@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"os"
+	"pickit/internal/utils"
+	"strings"
+	"text/tabwriter"
+)
+
+// 参数值的来源，优先级从高到低
+const (
+	sourceFlag    = "flag"
+	sourceEnv     = "env"
+	sourceProfile = "profile"
+	sourceConfig  = "config"
+	sourceDefault = "default"
+)
+
+var (
+	configPath    string          // 配置文件
+	profileName   string          // 使用的 profile
+	configApplied map[string]bool // 由环境变量或配置文件填充的参数
+)
+
+// flagSetting 参数的生效值及其来源
+type flagSetting struct {
+	Flag   string `json:"flag"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "查看配置",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show [命令]",
+	Short: "输出生效的参数值及其来源，不指定命令时输出所有命令",
+	Run: func(cmd *cobra.Command, args []string) {
+		path, profile, profileSection, defaults := loadConfig(cmd)
+		if !jsonOutput {
+			fmt.Printf(utils.T("配置文件: %s\n"), path)
+			if profile != "" {
+				fmt.Printf("profile: %s\n", profile)
+			}
+		}
+
+		commands := make([]*cobra.Command, 0)
+		if len(args) > 0 {
+			target, _, err := rootCmd.Find(args)
+			if err != nil || target == rootCmd {
+				fatalUsage("命令不存在", utils.Str("command", strings.Join(args, " ")))
+			}
+			commands = append(commands, target)
+		} else {
+			commands = runnableCommands(rootCmd)
+		}
+
+		names := make([]string, 0, len(commands))
+		settings := make(map[string][]flagSetting)
+		for _, c := range commands {
+			name := strings.TrimPrefix(c.CommandPath(), rootCmd.Name()+" ")
+			names = append(names, name)
+			for _, s := range resolveFlags(c, profileSection, defaults) {
+				if s.Flag == "password" && s.Value != "" {
+					s.Value = "******"
+				}
+				settings[name] = append(settings[name], s)
+			}
+		}
+		setData(map[string]any{"config": path, "profile": profile, "commands": settings})
+		if jsonOutput {
+			return
+		}
+
+		for _, name := range names {
+			fmt.Printf("\n[%s]\n", name)
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, utils.T("参数\t值\t来源"))
+			for _, s := range settings[name] {
+				fmt.Fprintf(w, "--%s\t%s\t%s\n", s.Flag, s.Value, s.Source)
+			}
+			_ = w.Flush()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd)
+}
+
+// loadConfig 读取配置文件，返回配置文件路径、使用的 profile 以及 profile 和 defaults 中的参数
+func loadConfig(cmd *cobra.Command) (string, string, utils.ConfigSection, utils.ConfigSection) {
+	flags := cmd.Root().PersistentFlags()
+	path := configPath
+	if !flags.Changed("config") && os.Getenv("PICKIT_CONFIG") != "" {
+		path = os.Getenv("PICKIT_CONFIG")
+	}
+	config, err := utils.LoadConfig(path)
+	if err != nil {
+		fatal("读取配置文件失败", utils.Str("path", path), utils.Err(err))
+	}
+
+	profile := profileName
+	if !flags.Changed("profile") {
+		profile = os.Getenv("PICKIT_PROFILE")
+		if profile == "" {
+			profile = config.Profile
+		}
+	}
+	section, err := config.Section(profile)
+	if err != nil {
+		fatalUsage("读取配置文件失败", utils.Err(err))
+	}
+	return path, profile, section, config.Defaults
+}
+
+// resolveFlags 计算命令每个参数的生效值，优先级: 命令行参数 > 环境变量 > profile > 配置文件 defaults > 内置默认值
+func resolveFlags(cmd *cobra.Command, profile, defaults utils.ConfigSection) []flagSetting {
+	command := commandKey(cmd)
+
+	settings := make([]flagSetting, 0)
+	cmd.LocalFlags() // 合并父命令的全局标志
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		switch f.Name {
+		case "help", "config", "profile":
+			return
+		}
+
+		s := flagSetting{Flag: f.Name, Value: f.Value.String(), Source: sourceDefault}
+		if f.Changed && !configApplied[f.Name] {
+			s.Source = sourceFlag
+		} else if value, ok := lookupEnv(command, f.Name); ok {
+			s.Value, s.Source = value, sourceEnv
+		} else if value, ok := profile.Lookup(command, f.Name); ok {
+			s.Value, s.Source = value, sourceProfile
+		} else if value, ok := defaults.Lookup(command, f.Name); ok {
+			s.Value, s.Source = value, sourceConfig
+		}
+		settings = append(settings, s)
+	})
+	return settings
+}
+
+// applyConfig 用环境变量和配置文件填充命令行中没有传入的参数
+func applyConfig(cmd *cobra.Command) {
+	_, _, profile, defaults := loadConfig(cmd)
+	configApplied = make(map[string]bool)
+	for _, s := range resolveFlags(cmd, profile, defaults) {
+		if s.Source == sourceFlag || s.Source == sourceDefault {
+			continue
+		}
+		if err := cmd.Flags().Set(s.Flag, s.Value); err != nil {
+			fatalUsage("配置中的参数值无效",
+				utils.Str("flag", s.Flag),
+				utils.Str("source", s.Source),
+				utils.Err(err))
+		}
+		configApplied[s.Flag] = true
+		utils.LogDebug("使用配置中的参数值", utils.Str("flag", s.Flag), utils.Str("source", s.Source))
+	}
+}
+
+// userFlag 判断参数是否由用户在命令行中传入
+func userFlag(cmd *cobra.Command, name string) bool {
+	return cmd.Flags().Changed(name) && !configApplied[name]
+}
+
+// lookupEnv 查找参数对应的环境变量: PICKIT_<命令>_<参数>，其次是 PICKIT_<参数>。
+// password 只支持带命令的形式，PICKIT_PASSWORD 保留给 login 使用
+func lookupEnv(command, flag string) (string, bool) {
+	names := make([]string, 0, 2)
+	if command != "" {
+		names = append(names, envName(command+"_"+flag))
+	}
+	if flag != "password" {
+		names = append(names, envName(flag))
+	}
+	for _, name := range names {
+		if value, ok := os.LookupEnv(name); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// envName 把参数名转换为环境变量名，如 url-template -> PICKIT_URL_TEMPLATE
+func envName(key string) string {
+	key = strings.NewReplacer("-", "_", ".", "_").Replace(key)
+	return "PICKIT_" + strings.ToUpper(key)
+}
+
+// commandKey 用 . 连接的命令路径，如 subscribe.add
+func commandKey(cmd *cobra.Command) string {
+	parts := strings.Fields(cmd.CommandPath())
+	if len(parts) <= 1 {
+		return ""
+	}
+	return strings.Join(parts[1:], ".")
+}
+
+// runnableCommands 列出所有可执行的命令
+func runnableCommands(cmd *cobra.Command) []*cobra.Command {
+	commands := make([]*cobra.Command, 0)
+	for _, c := range cmd.Commands() {
+		if c.Hidden || c.Name() == "help" || c.Name() == "completion" {
+			continue
+		}
+		if c.Runnable() {
+			commands = append(commands, c)
+		}
+		commands = append(commands, runnableCommands(c)...)
+	}
+	return commands
+}
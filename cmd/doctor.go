@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"log"
+	"os"
+	"pickit/internal/mode"
+	"pickit/internal/utils"
+	"text/tabwriter"
+)
+
+type doctorFlags struct {
+	cdn         string   // 图片域名地址
+	aid         aidValue // 示例页面的车牌号
+	proxy       string   // 魔法
+	output      string   // 输出目录
+	urlTemplate string   // 图片地址模板
+	ext         string   // 图片扩展名
+	fallbackExt []string // 备选扩展名
+	minFree     int64    // 至少需要的剩余空间（MB）
+}
+
+var doctorOpts doctorFlags
+
+// checkReport 单项诊断的结果，--json 时写入报告的 data
+type checkReport struct {
+	Name       string `json:"name"`
+	Target     string `json:"target,omitempty"`
+	Status     string `json:"status"` // pass、fail、skip
+	Detail     string `json:"detail,omitempty"`
+	Hint       string `json:"hint,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	DurationMs int64  `json:"duration_ms,omitempty"`
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "诊断 CDN、代理、网络和输出目录的问题",
+	Run: func(cmd *cobra.Command, args []string) {
+		tpl, err := utils.ParseUrlTemplate(doctorOpts.urlTemplate)
+		if err != nil {
+			fatalUsage("图片地址模板无效", utils.Err(err))
+		}
+		results, err := mode.Diagnose(cmd.Context(), mode.DoctorOptions{
+			Cdn:          doctorOpts.cdn,
+			Aid:          doctorOpts.aid.ref.Id,
+			Proxy:        doctorOpts.proxy,
+			Client:       newHTTPClient(doctorOpts.proxy),
+			UrlTemplate:  tpl,
+			Ext:          doctorOpts.ext,
+			FallbackExts: doctorOpts.fallbackExt,
+			Output:       doctorOpts.output,
+			MinFree:      doctorOpts.minFree << 20,
+		})
+		if err != nil {
+			fatalUsage("诊断失败", utils.Err(err))
+		}
+
+		data := make([]checkReport, 0, len(results))
+		failed := 0
+		for _, res := range results {
+			data = append(data, checkReport{
+				Name:       res.Name,
+				Target:     res.Target,
+				Status:     res.Status,
+				Detail:     res.Detail,
+				Hint:       res.Hint,
+				Error:      errString(res.Err),
+				Kind:       utils.ErrorKind(res.Err),
+				DurationMs: res.Duration.Milliseconds(),
+			})
+			if res.Failed() {
+				failed++
+			}
+		}
+		setData(data)
+
+		if !jsonOutput {
+			printChecks(results)
+		}
+		if failed > 0 {
+			fatal("诊断发现问题", utils.Int("failed", failed))
+		}
+	},
+}
+
+// printChecks 以表格输出诊断结果，未通过的项在表格后给出建议
+func printChecks(results []utils.CheckResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, utils.T("检查\t对象\t结果\t说明"))
+	for _, res := range results {
+		note := res.Detail
+		if note == "" {
+			note = errString(res.Err)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", res.Name, res.Target, res.Status, note)
+	}
+	_ = w.Flush()
+
+	for _, res := range results {
+		if res.Failed() {
+			fmt.Printf(utils.T("%s 未通过: %s\n"), res.Name, res.Hint)
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().StringVarP(&doctorOpts.cdn, "cdn", "u", "", "图片 cdn 域名（必传）")
+	doctorCmd.Flags().VarP(&doctorOpts.aid, "aid", "a", "请求该车牌号的第一页检查状态码，不传时只检查连接（可选）")
+	doctorCmd.Flags().StringVarP(&doctorOpts.proxy, "proxy", "p", "", "魔法（可选）")
+	doctorCmd.Flags().StringVarP(&doctorOpts.output, "output", "o", ".", "检查写入权限和剩余空间的输出目录（可选）")
+	doctorCmd.Flags().StringVar(&doctorOpts.urlTemplate, "url-template", utils.DefaultUrlTemplate, "图片地址模板，支持 {cdn} {aid} {page} {ext}，页码可带偏移和补零，如 {page-1:03}（可选）")
+	doctorCmd.Flags().StringVar(&doctorOpts.ext, "ext", utils.DefaultImageExt, "图片扩展名，用于 {ext} 占位符（可选）")
+	doctorCmd.Flags().StringSliceVar(&doctorOpts.fallbackExt, "fallback-ext", utils.DefaultFallbackExts, "图片 404 时依次尝试的备选扩展名，传空值关闭（可选）")
+	doctorCmd.Flags().Int64Var(&doctorOpts.minFree, "min-free", 1024, "输出目录所在磁盘至少需要的剩余空间，单位 MB（可选）")
+
+	if err := doctorCmd.MarkFlagRequired("cdn"); err != nil {
+		log.Fatalf("初始化失败: 无法标记 %s 为必需参数: %v", "cdn", err)
+	}
+}
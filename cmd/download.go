@@ -2,7 +2,8 @@ package cmd
 
 import (
 	"log"
-	"pickit/internal/mode"
+	"pickit/internal/utils"
+	"pickit/pkg/pickit"
 )
 
 import (
@@ -10,12 +11,20 @@ import (
 )
 
 type downloadFlags struct {
-	cdn         string // 图片域名地址
-	output      string // 输出路径
-	aid         int    // 车牌号
-	count       int    // 图片数量
-	concurrency int    // 并发数
-	proxy       string // 魔法
+	cdn         string   // 图片域名地址
+	output      string   // 输出路径
+	aid         aidValue // 车牌号
+	count       int      // 图片数量
+	concurrency int      // 并发数
+	proxy       string   // 魔法
+	plugin      string   // 插件名
+	urlTemplate string   // 图片地址模板
+	ext         string   // 图片扩展名
+	fallbackExt []string // 备选扩展名
+	chapters    string   // 章节范围
+	force       bool     // 重新下载已完成的页面
+	selection   selectionFlags
+	naming      namingFlags
 }
 
 var downloadOpts downloadFlags
@@ -24,8 +33,39 @@ var downloadCmd = &cobra.Command{
 	Use:   "download",
 	Short: "下载图片",
 	Run: func(cmd *cobra.Command, args []string) {
+		opts := pickit.DownloadOptions{
+			Album:        downloadOpts.aid.ref,
+			Cdn:          downloadOpts.cdn,
+			Output:       downloadOpts.output,
+			Count:        downloadOpts.count,
+			Concurrency:  downloadOpts.concurrency,
+			UrlTemplate:  downloadOpts.urlTemplate,
+			Ext:          downloadOpts.ext,
+			FallbackExts: downloadOpts.fallbackExt,
+			Chapters:     downloadOpts.chapters,
+			Pages:        downloadOpts.selection.pages,
+			Exclude:      downloadOpts.selection.exclude,
+			AlbumDir:     downloadOpts.naming.albumDir,
+			ChapterDir:   downloadOpts.naming.chapterDir,
+			Plugin:       downloadOpts.plugin,
+			Force:        downloadOpts.force,
+		}
+		client := newClient(downloadOpts.proxy)
+		if dryRun {
+			plan, err := client.PlanDownload(cmd.Context(), opts)
+			if err != nil {
+				fatalErr("生成下载计划失败", err)
+			}
+			reportPlan(plan)
+			return
+		}
+
 		// 下载
-		mode.DownloadAlbum(downloadOpts.cdn, downloadOpts.output, downloadOpts.proxy, downloadOpts.aid, downloadOpts.count, downloadOpts.concurrency)
+		results, err := client.Download(cmd.Context(), opts)
+		if err != nil {
+			fatalErr("下载失败", err)
+		}
+		addDownloadItems(results)
 	},
 }
 
@@ -35,13 +75,22 @@ func init() {
 	// 本地标志
 	downloadCmd.Flags().StringVarP(&downloadOpts.cdn, "cdn", "u", "", "图片 cdn 域名（必传）")
 	downloadCmd.Flags().StringVarP(&downloadOpts.output, "output", "o", "", "保存文件夹路径（必传）")
-	downloadCmd.Flags().IntVarP(&downloadOpts.aid, "aid", "a", 0, "车牌号（必传）")
-	downloadCmd.Flags().IntVarP(&downloadOpts.count, "count", "n", 0, "图片数量（必传）")
+	downloadCmd.Flags().VarP(&downloadOpts.aid, "aid", "a", "车牌号，支持数字、JM 编号和本子/章节链接（必传）")
+	downloadCmd.Flags().IntVarP(&downloadOpts.count, "count", "n", 0, "图片数量，不传时通过 --api 获取（可选）")
 	downloadCmd.Flags().IntVarP(&downloadOpts.concurrency, "concurrency", "c", 8, "并发数（可选）")
 	downloadCmd.Flags().StringVarP(&downloadOpts.proxy, "proxy", "p", "", "魔法（可选）")
+	downloadCmd.Flags().StringVar(&downloadOpts.urlTemplate, "url-template", utils.DefaultUrlTemplate, "图片地址模板，支持 {cdn} {aid} {page} {ext}，页码可带偏移和补零，如 {page-1:03}（可选）")
+	downloadCmd.Flags().StringVar(&downloadOpts.ext, "ext", utils.DefaultImageExt, "图片扩展名，用于 {ext} 占位符（可选）")
+	downloadCmd.Flags().StringSliceVar(&downloadOpts.fallbackExt, "fallback-ext", utils.DefaultFallbackExts, "图片 404 时依次尝试的备选扩展名，传空值关闭（可选）")
+	downloadCmd.Flags().StringVar(&downloadOpts.chapters, "chapters", "", "需要下载的章节范围，如 3-7,9,12-，需要 --api（可选）")
+	addSelectionFlags(downloadCmd, &downloadOpts.selection)
+	addNamingFlags(downloadCmd, &downloadOpts.naming)
+	addDryRunFlag(downloadCmd)
+	downloadCmd.Flags().BoolVar(&downloadOpts.force, "force", false, "重新下载清单中已完成的页面，默认跳过（可选）")
+	downloadCmd.Flags().StringVar(&downloadOpts.plugin, "plugin", "", "提供下载地址的插件名（可选）")
 
-	// cdn、output、aid、count 这四个是必传的
-	requiredFlags := []string{"cdn", "output", "aid", "count"}
+	// cdn、output、aid 这三个是必传的
+	requiredFlags := []string{"cdn", "output", "aid"}
 	for _, flag := range requiredFlags {
 		if err := downloadCmd.MarkFlagRequired(flag); err != nil {
 			log.Fatalf("初始化失败: 无法标记 %s 为必需参数: %v", flag, err)
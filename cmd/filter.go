@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"pickit/internal/utils"
+)
+
+// fileFilterFlags 读取图片目录时的文件过滤标志
+type fileFilterFlags struct {
+	include []string // 只读取匹配的文件
+	exclude []string // 跳过匹配的文件
+}
+
+// addFileFilterFlags 为命令添加 --include-files 和 --exclude-files
+func addFileFilterFlags(cmd *cobra.Command, flags *fileFilterFlags) {
+	cmd.Flags().StringSliceVar(&flags.include, "include-files", nil, "只读取文件名或相对路径匹配的文件，如 *.jpg，可以用逗号分隔多个（可选）")
+	cmd.Flags().StringSliceVar(&flags.exclude, "exclude-files", nil, "跳过文件名或相对路径匹配的文件和章节目录，如 cover.*，可以用逗号分隔多个（可选）")
+}
+
+// skippedReport 读取图片目录时跳过的文件，--json 时写入报告的 data
+type skippedReport struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"` // hidden、temp、excluded、not_image、layout
+	Dir    bool   `json:"dir,omitempty"`
+}
+
+func newSkippedReport(skipped []utils.SkippedFile) []skippedReport {
+	report := make([]skippedReport, 0, len(skipped))
+	for _, s := range skipped {
+		report = append(report, skippedReport{Path: s.Path, Reason: s.Reason, Dir: s.IsDir})
+	}
+	return report
+}
+
+// reportSkipped 输出还原或合成 PDF 时跳过的文件，--json 时写入报告的 data.skipped
+func reportSkipped(skipped []utils.SkippedFile) {
+	if len(skipped) == 0 {
+		return
+	}
+	setData(struct {
+		Skipped []skippedReport `json:"skipped"`
+	}{newSkippedReport(skipped)})
+	if !jsonOutput {
+		printSkipped(skipped)
+	}
+}
+
+// printSkipped 逐行输出跳过的文件及原因
+func printSkipped(skipped []utils.SkippedFile) {
+	for _, s := range skipped {
+		fmt.Printf(utils.T("跳过 %s: %s\n"), s.Path, s.Describe())
+	}
+}
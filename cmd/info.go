@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"pickit/internal/mode"
+	"pickit/internal/utils"
+	"text/tabwriter"
+)
+
+type infoFlags struct {
+	aid   aidValue // 计算切割刀数使用的车牌号
+	files fileFilterFlags
+}
+
+var infoOpts infoFlags
+
+// infoReport 目录信息，--json 时写入报告的 data
+type infoReport struct {
+	Dir      string              `json:"dir"`
+	Aid      int                 `json:"aid,omitempty"`
+	Chapters []infoReportChapter `json:"chapters"`
+	Pages    int                 `json:"pages"`
+	Size     int64               `json:"size"`
+	Skipped  []skippedReport     `json:"skipped"`
+	Warnings []string            `json:"warnings"`
+}
+
+type infoReportChapter struct {
+	Name  string           `json:"name"` // 单层目录时为空
+	Pages []infoReportPage `json:"pages"`
+	Size  int64            `json:"size"`
+}
+
+type infoReportPage struct {
+	Path     string `json:"path"`
+	Format   string `json:"format,omitempty"`
+	Width    int    `json:"width,omitempty"`
+	Height   int    `json:"height,omitempty"`
+	Size     int64  `json:"size"`
+	Segments *int   `json:"segments,omitempty"` // 没有传入车牌号时不输出
+	Error    string `json:"error,omitempty"`
+}
+
+var infoCmd = &cobra.Command{
+	Use:   "info <目录>",
+	Short: "查看目录中的章节、页数、图片尺寸和格式，检查会影响合成 PDF 的问题",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		filter, err := utils.NewFileFilter(infoOpts.files.include, infoOpts.files.exclude)
+		if err != nil {
+			fatalUsage("文件过滤规则无效", utils.Err(err))
+		}
+		info, err := mode.InspectDir(args[0], infoOpts.aid.ref.Id, filter)
+		if err != nil {
+			fatal("查看目录信息失败", utils.Err(err))
+		}
+
+		data := infoReport{Dir: info.Dir, Aid: info.Aid, Pages: info.Pages, Size: info.Size,
+			Skipped: newSkippedReport(info.Skipped), Warnings: info.Warnings}
+		for _, chapter := range info.Chapters {
+			c := infoReportChapter{Name: chapter.Name, Size: chapter.Size, Pages: make([]infoReportPage, 0, len(chapter.Pages))}
+			for _, page := range chapter.Pages {
+				p := infoReportPage{Path: page.Path, Format: page.Format, Width: page.Width, Height: page.Height, Size: page.Size, Error: errString(page.Err)}
+				if page.Segments >= 0 {
+					segments := page.Segments
+					p.Segments = &segments
+				}
+				c.Pages = append(c.Pages, p)
+			}
+			data.Chapters = append(data.Chapters, c)
+		}
+		setData(data)
+		if !jsonOutput {
+			printInfo(info)
+		}
+	},
+}
+
+// printInfo 以表格输出每一页的信息，之后输出汇总和警告
+func printInfo(info *mode.AlbumInfo) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, utils.T("章节\t文件\t格式\t尺寸\t大小\t切割"))
+	for _, chapter := range info.Chapters {
+		for _, page := range chapter.Pages {
+			format, dimensions, segments := page.Format, "", ""
+			if page.Err != nil {
+				format = utils.T("非图片")
+			} else {
+				dimensions = fmt.Sprintf("%dx%d", page.Width, page.Height)
+			}
+			if page.Segments >= 0 {
+				segments = fmt.Sprint(page.Segments)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", chapter.Name, filepath.Base(page.Path), format, dimensions, formatSize(page.Size), segments)
+		}
+	}
+	_ = w.Flush()
+
+	chapters := len(info.Chapters)
+	if chapters == 1 && info.Chapters[0].Name == "" {
+		chapters = 0
+	}
+	fmt.Printf(utils.T("%d 个章节，%d 页，总大小 %s\n"), chapters, info.Pages, formatSize(info.Size))
+	printSkipped(info.Skipped)
+	for _, warning := range info.Warnings {
+		fmt.Printf(utils.T("警告: %s\n"), warning)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(infoCmd)
+
+	infoCmd.Flags().VarP(&infoOpts.aid, "aid", "a", "车牌号，传入时按文件名计算每一页的切割刀数（可选）")
+	addFileFilterFlags(infoCmd, &infoOpts.files)
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"pickit/internal/mode"
+	"pickit/internal/utils"
+	"text/tabwriter"
+	"time"
+)
+
+type jobsFlags struct {
+	all         bool   // 列出已完成和已取消的任务
+	concurrency int    // 并发数
+	proxy       string // 魔法
+}
+
+var (
+	jobsOpts jobsFlags
+	jobDir   string // 任务目录
+)
+
+var jobsCmd = &cobra.Command{
+	Use:   "jobs",
+	Short: "管理批量任务，中断的任务可以继续执行",
+}
+
+var jobsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "列出任务",
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs, err := openJobStore().List()
+		if err != nil {
+			fatal("读取任务失败", utils.Err(err))
+		}
+
+		listed := make([]*utils.Job, 0, len(jobs))
+		for _, job := range jobs {
+			if jobsOpts.all || job.Status != utils.JobDone && job.Status != utils.JobCancelled {
+				listed = append(listed, job)
+			}
+		}
+		setData(listed)
+		if jsonOutput {
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, utils.T("任务\t车牌号\t状态\t导出\t进度\t失败\t更新时间\t输出目录\t错误"))
+		for _, job := range listed {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d/%d\t%d\t%s\t%s\t%s\n",
+				job.Id, job.Input, job.Status, job.Export,
+				job.Progress(), len(job.Pages), job.FailedPages(),
+				job.UpdatedAt.Local().Format(time.DateTime), job.Output, job.Error)
+		}
+		_ = w.Flush()
+	},
+}
+
+var jobsRetryCmd = &cobra.Command{
+	Use:   "retry [任务]...",
+	Short: "继续执行任务，不指定任务时执行所有未完成的任务",
+	Run: func(cmd *cobra.Command, args []string) {
+		store := openJobStore()
+		jobs := make([]*utils.Job, 0)
+		if len(args) == 0 {
+			all, err := store.List()
+			if err != nil {
+				fatal("读取任务失败", utils.Err(err))
+			}
+			for _, job := range all {
+				if job.Status != utils.JobDone && job.Status != utils.JobCancelled {
+					jobs = append(jobs, job)
+				}
+			}
+		} else {
+			for _, id := range args {
+				job, err := store.Get(id)
+				if err != nil {
+					fatal("读取任务失败", utils.Err(err))
+				}
+				if job.Status == utils.JobCancelled {
+					// 明确指定的已取消任务重新开始执行
+					job.Status = utils.JobPending
+				}
+				jobs = append(jobs, job)
+			}
+		}
+		if len(jobs) == 0 {
+			utils.LogInfo("没有需要执行的任务")
+			return
+		}
+
+		mode.RunJobs(cmd.Context(), store, jobs, newMetadataProvider(jobsOpts.proxy), jobsOpts.concurrency, newHTTPClient(jobsOpts.proxy))
+
+		results := make([]mode.BatchJobResult, 0, len(jobs))
+		for _, job := range jobs {
+			results = append(results, mode.JobResult(job))
+		}
+		addBatchItems(results)
+		if !jsonOutput {
+			printBatchSummary(results)
+		}
+	},
+}
+
+var jobsCancelCmd = &cobra.Command{
+	Use:   "cancel <任务>...",
+	Short: "取消任务，已下载的文件会保留",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store := openJobStore()
+		for _, id := range args {
+			job, err := store.Get(id)
+			if err != nil {
+				fatal("读取任务失败", utils.Err(err))
+			}
+			if job.Status == utils.JobDone {
+				utils.LogWarn("任务已完成，无需取消", utils.Str("job", id))
+				addItem(reportItem{Input: id, Output: job.Output, Status: statusFailed, Error: utils.T("任务已完成")})
+				continue
+			}
+			job.Status = utils.JobCancelled
+			if err := store.Save(job); err != nil {
+				fatal("保存任务失败", utils.Err(err))
+			}
+			utils.LogInfo("任务已取消", utils.Str("job", id))
+			addItem(reportItem{Input: id, Output: job.Output, Status: statusOK})
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(jobsCmd)
+	jobsCmd.AddCommand(jobsListCmd, jobsRetryCmd, jobsCancelCmd)
+
+	jobsCmd.PersistentFlags().StringVar(&jobDir, "jobs", utils.DefaultJobDir(), "任务目录（可选）")
+
+	// 本地标志
+	jobsListCmd.Flags().BoolVarP(&jobsOpts.all, "all", "a", false, "同时列出已完成和已取消的任务（可选）")
+	jobsRetryCmd.Flags().IntVarP(&jobsOpts.concurrency, "concurrency", "c", 8, "并发数（可选）")
+	jobsRetryCmd.Flags().StringVarP(&jobsOpts.proxy, "proxy", "p", "", "魔法（可选）")
+}
+
+// openJobStore 打开任务目录
+func openJobStore() *utils.JobStore {
+	store, err := utils.OpenJobStore(jobDir)
+	if err != nil {
+		fatal("打开任务目录失败", utils.Err(err))
+	}
+	return store
+}
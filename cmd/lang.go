@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"pickit/internal/utils"
+	"strings"
+)
+
+var lang string // 界面语言
+
+// setupLocale 在解析参数之前确定界面语言并翻译命令帮助，--lang 优先于 LC_ALL、LC_MESSAGES、LANG 环境变量
+func setupLocale(args []string) {
+	if err := utils.SetLocale(langArg(args)); err != nil {
+		_ = utils.SetLocale(utils.DetectLocale())
+	}
+	localizeCommand(rootCmd)
+}
+
+// langArg 从命令行参数中找到 --lang 的值，没有时返回根据环境变量推断的语言
+func langArg(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if value, ok := strings.CutPrefix(arg, "--lang="); ok {
+			return value
+		}
+		if arg == "--lang" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return utils.DetectLocale()
+}
+
+// applyLocale 使用解析后的 --lang（可能来自配置文件或环境变量）重新设置语言
+func applyLocale() {
+	if lang == "" {
+		return
+	}
+	if err := utils.SetLocale(lang); err != nil {
+		fatalUsage("界面语言无效", utils.Err(err))
+	}
+}
+
+// localizeCommand 按当前语言翻译命令及其子命令的说明和参数帮助
+func localizeCommand(cmd *cobra.Command) {
+	cmd.Use = utils.T(cmd.Use)
+	cmd.Short = utils.T(cmd.Short)
+	cmd.Long = utils.T(cmd.Long)
+	localizeFlag := func(f *pflag.Flag) {
+		f.Usage = utils.T(f.Usage)
+	}
+	cmd.PersistentFlags().VisitAll(localizeFlag)
+	cmd.LocalNonPersistentFlags().VisitAll(localizeFlag)
+	for _, sub := range cmd.Commands() {
+		localizeCommand(sub)
+	}
+}
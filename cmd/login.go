@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/spf13/cobra"
+	"log"
+	"os"
+	"pickit/internal/utils"
+	"strings"
+)
+
+type loginFlags struct {
+	username string // 用户名
+	password string // 密码
+	proxy    string // 魔法
+}
+
+var loginOpts loginFlags
+
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "登录并保存登录状态",
+	// 登录时不加载已保存的登录状态
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		setupCommand(cmd)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		password := loginOpts.password
+		if password == "" {
+			password = os.Getenv("PICKIT_PASSWORD")
+		}
+		if password == "" {
+			fmt.Fprint(os.Stderr, utils.T("密码: "))
+			line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			password = strings.TrimRight(line, "\r\n")
+		}
+
+		session, err := utils.Login(apiBase, loginOpts.proxy, loginOpts.username, password)
+		if err != nil {
+			fatal("登录失败", utils.Err(err))
+		}
+		if err := utils.SaveSession(sessionPath, session); err != nil {
+			fatal("保存登录状态失败", utils.Err(err))
+		}
+		utils.LogInfo("登录状态已保存", utils.Str("path", sessionPath))
+		setData(map[string]any{"username": session.Username, "expires_at": session.ExpiresAt, "session": sessionPath})
+	},
+}
+
+var logoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "删除已保存的登录状态",
+	// 退出登录时不加载已保存的登录状态
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		setupCommand(cmd)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := utils.RemoveSession(sessionPath); err != nil {
+			fatal("退出登录失败", utils.Err(err))
+		}
+		utils.LogInfo("已删除登录状态", utils.Str("path", sessionPath))
+		setData(map[string]any{"session": sessionPath})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(loginCmd)
+	rootCmd.AddCommand(logoutCmd)
+
+	// 本地标志
+	loginCmd.Flags().StringVarP(&loginOpts.username, "username", "U", "", "用户名（必传）")
+	loginCmd.Flags().StringVarP(&loginOpts.password, "password", "P", "", "密码，不传时读取 PICKIT_PASSWORD 或从终端输入（可选）")
+	loginCmd.Flags().StringVarP(&loginOpts.proxy, "proxy", "p", "", "魔法（可选）")
+
+	if err := loginCmd.MarkFlagRequired("username"); err != nil {
+		log.Fatalf("初始化失败: 无法标记 %s 为必需参数: %v", "username", err)
+	}
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"pickit/internal/utils"
+)
+
+// namingFlags 命名模板标志
+type namingFlags struct {
+	albumDir   string // 本子目录
+	chapterDir string // 章节目录
+}
+
+// addNamingFlags 为命令添加 --album-dir 和 --chapter-dir，下载的原图保持原文件名
+func addNamingFlags(cmd *cobra.Command, flags *namingFlags) {
+	cmd.Flags().StringVar(&flags.albumDir, "album-dir", utils.DefaultAlbumDirTemplate, "输出目录下的本子目录模板，如 \"{aid} {title}\"，为空时直接保存到输出目录（可选）")
+	cmd.Flags().StringVar(&flags.chapterDir, "chapter-dir", utils.DefaultChapterDirTemplate, "多章节本子的章节目录模板，需要包含 {chapter}（可选）")
+}
+
+// addPageNameFlag 为命令添加 --page-name，只用于还原后的图片
+func addPageNameFlag(cmd *cobra.Command, pageName *string) {
+	cmd.Flags().StringVar(pageName, "page-name", utils.DefaultPageNameTemplate, "还原后的图片文件名模板，支持 {aid} {page} {name} {ext}，如 {page:03}.{ext}（可选）")
+}
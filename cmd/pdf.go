@@ -1,15 +1,20 @@
 package cmd
 
 import (
+	"fmt"
 	"github.com/spf13/cobra"
 	"log"
-	"pickit/internal/mode"
+	"pickit/internal/utils"
+	"pickit/pkg/pickit"
+	"time"
 )
 
 type pdfFlags struct {
-	input    string // 输入路径
-	output   string // 输出路径
-	password string // 密码
+	input     string // 输入路径
+	output    string // 输出路径
+	password  string // 密码
+	selection selectionFlags
+	files     fileFilterFlags
 }
 
 var pdfOpts pdfFlags
@@ -18,7 +23,60 @@ var cmdPdf = &cobra.Command{
 	Use:   "pdf",
 	Short: "合成 PDF",
 	Run: func(cmd *cobra.Command, args []string) {
-		mode.CreatePDF(pdfOpts.input, pdfOpts.output, pdfOpts.password)
+		opts := pickit.PDFOptions{
+			Input:        pdfOpts.input,
+			Output:       pdfOpts.output,
+			Password:     pdfOpts.password,
+			Pages:        pdfOpts.selection.pages,
+			Exclude:      pdfOpts.selection.exclude,
+			IncludeFiles: pdfOpts.files.include,
+			ExcludeFiles: pdfOpts.files.exclude,
+		}
+		client := newClient("")
+		if dryRun {
+			plan, err := client.PlanPDF(opts)
+			if err != nil {
+				fatalErr("生成合成计划失败", err)
+			}
+			reportPlan(plan)
+			return
+		}
+
+		// 统计无法加入 PDF 的图片，合成时会跳过这些图片
+		var pages, failed int
+		var firstErr error
+		opts.Events = pickit.EventFunc(func(e pickit.Event) {
+			switch {
+			case e.Type == pickit.EventItemFailed && firstErr == nil:
+				firstErr = e.Err
+			case e.Type == pickit.EventJobFinished:
+				pages, failed = e.Total, e.Failed
+			}
+		})
+
+		start := time.Now()
+		skipped, err := client.CreatePDF(cmd.Context(), opts)
+		if err != nil {
+			fatalErr("Failed to convert images to pdf", err)
+		}
+		item := reportItem{
+			Input:      pdfOpts.input,
+			Output:     pdfOpts.output,
+			Status:     statusOK,
+			DurationMs: time.Since(start).Milliseconds(),
+			Pages:      pages,
+			Failed:     failed,
+		}
+		if failed > 0 {
+			item.Status = statusPartial
+			if failed == pages {
+				item.Status = statusFailed
+			}
+			item.Error = fmt.Sprintf(utils.T("%d 张图片无法加入 PDF"), failed)
+			item.Kind = utils.ErrorKind(firstErr)
+		}
+		addItem(item)
+		reportSkipped(skipped)
 	},
 }
 
@@ -29,6 +87,9 @@ func init() {
 	cmdPdf.Flags().StringVarP(&pdfOpts.input, "input", "i", "", "需要还原的图片文件夹路径（必传）")
 	cmdPdf.Flags().StringVarP(&pdfOpts.output, "output", "o", "", "还原后的图片输出文件夹路径（必传）")
 	cmdPdf.Flags().StringVarP(&pdfOpts.password, "password", "p", "", "密码（可选）")
+	addSelectionFlags(cmdPdf, &pdfOpts.selection)
+	addFileFilterFlags(cmdPdf, &pdfOpts.files)
+	addDryRunFlag(cmdPdf)
 
 	// input、output 这两个是必传的
 	requiredFlags := []string{"input", "output"}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"pickit/internal/utils"
+	"pickit/pkg/pickit"
+	"text/tabwriter"
+	"time"
+)
+
+var dryRun bool // 演练模式
+
+// planReport --json 时输出的执行计划
+type planReport struct {
+	Items       []planReportItem `json:"items"`
+	Downloads   int              `json:"downloads,omitempty"`    // 下载的页数
+	TotalBytes  int64            `json:"total_bytes,omitempty"`  // 已知大小的下载总量
+	UnknownSize int              `json:"unknown_size,omitempty"` // 无法获取大小的下载数
+	Concurrency int              `json:"concurrency,omitempty"`
+	EstimateMs  int64            `json:"estimate_ms,omitempty"` // 预计下载耗时
+	Overwrites  int              `json:"overwrites"`            // 会被覆盖的文件数
+	Completed   int              `json:"completed,omitempty"`   // 清单中已完成、跳过的项数
+	Failed      int              `json:"failed"`                // 无法执行的项数
+	Skipped     []skippedReport  `json:"skipped,omitempty"`     // 读取图片目录时跳过的文件
+}
+
+type planReportItem struct {
+	Stage   string `json:"stage"`
+	Input   string `json:"input"`
+	Output  string `json:"output,omitempty"`
+	Exists  bool   `json:"exists,omitempty"`
+	Skipped bool   `json:"skipped,omitempty"` // 清单中已完成，实际执行时跳过
+	Size    *int64 `json:"size,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Kind    string `json:"kind,omitempty"`
+}
+
+// addDryRunFlag 添加 --dry-run 参数
+func addDryRunFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "只列出计划执行的操作和输出路径，标出会被覆盖的文件，不写入任何文件（可选）")
+}
+
+// reportPlan 输出执行计划，--json 时写入报告的 data
+func reportPlan(plan *pickit.Plan) {
+	data := planReport{
+		Items:       make([]planReportItem, 0, len(plan.Items)),
+		Downloads:   plan.Downloads,
+		TotalBytes:  plan.TotalSize,
+		UnknownSize: plan.UnknownSize,
+		Concurrency: plan.Concurrency,
+		EstimateMs:  plan.Estimate.Milliseconds(),
+		Overwrites:  plan.Overwrites(),
+		Completed:   plan.Completed(),
+		Failed:      plan.Failed(),
+		Skipped:     newSkippedReport(plan.Skipped),
+	}
+	for _, item := range plan.Items {
+		r := planReportItem{
+			Stage:   item.Stage,
+			Input:   item.Input,
+			Output:  item.Output,
+			Exists:  item.Exists,
+			Skipped: item.Skipped,
+			Error:   errString(item.Err),
+			Kind:    utils.ErrorKind(item.Err),
+		}
+		if item.Size >= 0 {
+			size := item.Size
+			r.Size = &size
+		}
+		data.Items = append(data.Items, r)
+	}
+	setData(data)
+	if jsonOutput {
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, utils.T("阶段\t输入\t输出\t大小\t说明"))
+	for _, item := range plan.Items {
+		size := ""
+		if item.Size >= 0 {
+			size = formatSize(item.Size)
+		}
+		note := ""
+		switch {
+		case item.Err != nil:
+			note = item.Err.Error()
+		case item.Skipped:
+			note = utils.T("清单中已完成，跳过")
+		case item.Exists:
+			note = utils.T("覆盖已有文件")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", item.Stage, item.Input, item.Output, size, note)
+	}
+	_ = w.Flush()
+
+	fmt.Printf(utils.T("共 %d 项，%d 个已有文件会被覆盖，%d 项无法执行\n"), len(plan.Items), data.Overwrites, data.Failed)
+	if data.Completed > 0 {
+		fmt.Printf(utils.T("%d 项在清单中已完成，将被跳过，使用 --force 重新处理\n"), data.Completed)
+	}
+	printSkipped(plan.Skipped)
+	if plan.Downloads == 0 {
+		return
+	}
+	fmt.Printf(utils.T("下载 %d 页，总大小 %s，%d 页大小未知\n"), plan.Downloads, formatSize(plan.TotalSize), plan.UnknownSize)
+	if plan.Estimate > 0 {
+		estimate := plan.Estimate.Round(time.Second)
+		if plan.Estimate < time.Second {
+			estimate = plan.Estimate.Round(time.Millisecond)
+		}
+		fmt.Printf(utils.T("按 %d 并发预计耗时 %s\n"), plan.Concurrency, estimate)
+	} else {
+		fmt.Println(utils.T("无法估算下载耗时"))
+	}
+}
+
+// formatSize 以 KB、MB、GB 显示文件大小
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value, suffix := float64(size)/unit, "KB"
+	for _, s := range []string{"MB", "GB", "TB"} {
+		if value < unit {
+			break
+		}
+		value, suffix = value/unit, s
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
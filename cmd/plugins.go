@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"pickit/internal/utils"
+	"strings"
+)
+
+var pluginsCmd = &cobra.Command{
+	Use:   "plugins",
+	Short: "列出插件目录中的插件",
+	Run: func(cmd *cobra.Command, args []string) {
+		plugins, err := utils.DiscoverPlugins(pluginDir)
+		if err != nil {
+			fatal("扫描插件失败", utils.Err(err))
+		}
+		if len(plugins) == 0 && !jsonOutput {
+			fmt.Printf(utils.T("插件目录 %s 中没有插件\n"), pluginDir)
+			return
+		}
+
+		type pluginInfo struct {
+			Name         string   `json:"name"`
+			Path         string   `json:"path"`
+			Capabilities []string `json:"capabilities"`
+			Error        string   `json:"error,omitempty"`
+		}
+		infos := make([]pluginInfo, 0, len(plugins))
+		for _, p := range plugins {
+			err := p.Describe(cmd.Context())
+			infos = append(infos, pluginInfo{Name: p.Name, Path: p.Path, Capabilities: p.Capabilities, Error: errString(err)})
+			if jsonOutput {
+				continue
+			}
+			if err != nil {
+				fmt.Printf(utils.T("%s\t%s\t不可用: %v\n"), p.Name, p.Path, err)
+				continue
+			}
+			fmt.Printf("%s\t%s\t%s\n", p.Name, p.Path, strings.Join(p.Capabilities, ","))
+		}
+		setData(infos)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pluginsCmd)
+}
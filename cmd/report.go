@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"pickit/internal/mode"
+	"pickit/internal/utils"
+	"pickit/pkg/pickit"
+	"strings"
+	"time"
+)
+
+// 退出码
+const (
+	exitOK      = 0 // 全部成功
+	exitFailure = 1 // 全部失败或执行出错
+	exitUsage   = 2 // 参数错误
+	exitPartial = 3 // 部分失败
+)
+
+// 处理状态
+const (
+	statusOK      = "ok"
+	statusPartial = "partial"
+	statusFailed  = "failed"
+	statusSkipped = "skipped" // 清单中已完成，没有重新处理
+)
+
+var jsonOutput bool // 以 JSON 输出执行报告
+
+// report 命令的执行报告，--json 时输出到标准输出
+type report struct {
+	Command    string       `json:"command"`
+	Status     string       `json:"status"`
+	ExitCode   int          `json:"exit_code"`
+	StartedAt  time.Time    `json:"started_at"`
+	DurationMs int64        `json:"duration_ms"`
+	Items      []reportItem `json:"items,omitempty"` // 逐项处理结果
+	Data       any          `json:"data,omitempty"`  // 查询类命令的输出
+	Error      string       `json:"error,omitempty"` // 导致命令失败的错误
+	Kind       string       `json:"kind,omitempty"`  // 错误的类别，如 not_found、rate_limited
+}
+
+// reportItem 单项处理结果，如一页图片或一个本子
+type reportItem struct {
+	Input      string `json:"input,omitempty"`  // 下载地址、源文件或车牌号
+	Output     string `json:"output,omitempty"` // 输出路径
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	Kind       string `json:"kind,omitempty"` // 错误的类别，如 not_found、corrupt_image
+	DurationMs int64  `json:"duration_ms,omitempty"`
+	Pages      int    `json:"pages,omitempty"`    // 本子或 PDF 的页数
+	Failed     int    `json:"failed,omitempty"`   // 失败的页数
+	Chapters   int    `json:"chapters,omitempty"` // 同步的章节数
+}
+
+var cmdReport *report
+
+// startReport 开始记录命令的执行报告
+func startReport(cmd *cobra.Command) {
+	cmdReport = &report{
+		Command:   strings.ReplaceAll(commandKey(cmd), ".", " "),
+		StartedAt: time.Now(),
+	}
+}
+
+// addItem 记录单项处理结果
+func addItem(item reportItem) {
+	if cmdReport != nil {
+		cmdReport.Items = append(cmdReport.Items, item)
+	}
+}
+
+// setData 记录查询类命令的输出
+func setData(data any) {
+	if cmdReport != nil {
+		cmdReport.Data = data
+	}
+}
+
+// addDownloadItems 记录每一页的下载结果
+func addDownloadItems(results []utils.BatchDownloadResult) {
+	for _, res := range results {
+		addItem(reportItem{
+			Input:      res.Url,
+			Output:     res.Dist,
+			Status:     resultStatus(res.Err, res.Skipped),
+			Error:      errString(res.Err),
+			Kind:       utils.ErrorKind(res.Err),
+			DurationMs: res.Duration.Milliseconds(),
+		})
+	}
+}
+
+// addRestoreItems 记录每张图片的还原结果
+func addRestoreItems(results []utils.DecodeAndSaveResult) {
+	for _, res := range results {
+		addItem(reportItem{
+			Input:      res.ImgSrcPath,
+			Output:     res.DecodedSavePath,
+			Status:     resultStatus(res.Err, res.Skipped),
+			Error:      errString(res.Err),
+			Kind:       utils.ErrorKind(res.Err),
+			DurationMs: res.Duration.Milliseconds(),
+		})
+	}
+}
+
+// addBatchItems 记录每个本子的处理结果
+func addBatchItems(results []mode.BatchJobResult) {
+	for _, r := range results {
+		addItem(reportItem{
+			Input:  r.Input,
+			Output: r.Output,
+			Status: r.Status(),
+			Error:  errString(r.Err),
+			Kind:   utils.ErrorKind(r.Err),
+			Pages:  r.Pages,
+			Failed: r.Failed,
+		})
+	}
+}
+
+// albumItem 汇总一个本子的下载结果
+func albumItem(input, output string, results []utils.BatchDownloadResult, err error) reportItem {
+	item := reportItem{Input: input, Output: output, Status: statusOK, Error: errString(err), Kind: utils.ErrorKind(err), Pages: len(results)}
+	for _, res := range results {
+		if res.Err != nil {
+			item.Failed++
+		}
+	}
+	switch {
+	case err != nil || item.Pages > 0 && item.Failed == item.Pages:
+		item.Status = statusFailed
+	case item.Failed > 0:
+		item.Status = statusPartial
+	}
+	return item
+}
+
+// finishReport 根据处理结果确定状态和退出码，--json 时输出报告
+func finishReport(code int) int {
+	if cmdReport == nil {
+		cmdReport = &report{StartedAt: time.Now()}
+	}
+
+	if code == exitOK {
+		failed, partial := 0, 0
+		for _, item := range cmdReport.Items {
+			switch item.Status {
+			case statusFailed:
+				failed++
+			case statusPartial:
+				partial++
+			}
+		}
+		switch {
+		case len(cmdReport.Items) > 0 && failed == len(cmdReport.Items):
+			code = exitFailure
+		case failed > 0 || partial > 0:
+			code = exitPartial
+		}
+	}
+
+	switch code {
+	case exitOK:
+		cmdReport.Status = statusOK
+	case exitPartial:
+		cmdReport.Status = statusPartial
+	default:
+		cmdReport.Status = statusFailed
+	}
+	cmdReport.ExitCode = code
+	cmdReport.DurationMs = time.Since(cmdReport.StartedAt).Milliseconds()
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(cmdReport)
+	}
+	return code
+}
+
+// exitWithReport 输出报告并按处理结果退出
+func exitWithReport() {
+	if code := finishReport(exitOK); code != exitOK {
+		utils.SyncLogger()
+		os.Exit(code)
+	}
+}
+
+// fatal 记录错误并以 exitFailure 退出
+func fatal(msg string, fields ...zap.Field) {
+	fatalWith(exitFailure, msg, fields...)
+}
+
+// fatalUsage 记录参数错误并以 exitUsage 退出
+func fatalUsage(msg string, fields ...zap.Field) {
+	fatalWith(exitUsage, msg, fields...)
+}
+
+// fatalErr 记录错误并退出，参数无效时以 exitUsage 退出，否则以 exitFailure 退出
+func fatalErr(msg string, err error) {
+	if errors.Is(err, pickit.ErrInvalidOption) {
+		fatalUsage(msg, utils.Err(err))
+	}
+	fatal(msg, utils.Err(err))
+}
+
+func fatalWith(code int, msg string, fields ...zap.Field) {
+	utils.LogError(msg, fields...)
+	if cmdReport == nil {
+		cmdReport = &report{StartedAt: time.Now()}
+	}
+	cmdReport.Error = utils.T(msg)
+	for _, f := range fields {
+		if f.Type == zapcore.ErrorType {
+			if err, ok := f.Interface.(error); ok {
+				cmdReport.Error = utils.T(msg) + ": " + err.Error()
+				cmdReport.Kind = utils.ErrorKind(err)
+			}
+		}
+	}
+	finishReport(code)
+	utils.SyncLogger()
+	os.Exit(code)
+}
+
+// errStatus 根据错误返回处理状态
+func errStatus(err error) string {
+	if err != nil {
+		return statusFailed
+	}
+	return statusOK
+}
+
+// resultStatus 单页的处理状态，跳过的页面为 statusSkipped
+func resultStatus(err error, skipped bool) string {
+	if skipped {
+		return statusSkipped
+	}
+	return errStatus(err)
+}
+
+// errString 错误信息，没有错误时返回空字符串
+func errString(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return ""
+}
@@ -2,7 +2,7 @@ package cmd
 
 import (
 	"log"
-	"pickit/internal/mode"
+	"pickit/pkg/pickit"
 )
 
 import (
@@ -10,10 +10,15 @@ import (
 )
 
 type restoreFlags struct {
-	input       string // 输入路径
-	output      string // 输出路径
-	aid         int    // 车牌号
-	concurrency int    // 并发数
+	input       string   // 输入路径
+	output      string   // 输出路径
+	aid         aidValue // 车牌号
+	concurrency int      // 并发数
+	plugin      string   // 插件名
+	pageName    string   // 输出文件名模板
+	force       bool     // 重新还原已完成的图片
+	selection   selectionFlags
+	files       fileFilterFlags
 }
 
 var restoreOpts restoreFlags
@@ -22,8 +27,36 @@ var restoreCmd = &cobra.Command{
 	Use:   "restore",
 	Short: "还原图片",
 	Run: func(cmd *cobra.Command, args []string) {
+		opts := pickit.RestoreOptions{
+			Input:        restoreOpts.input,
+			Output:       restoreOpts.output,
+			Aid:          restoreOpts.aid.ref.Id,
+			Concurrency:  restoreOpts.concurrency,
+			Pages:        restoreOpts.selection.pages,
+			Exclude:      restoreOpts.selection.exclude,
+			IncludeFiles: restoreOpts.files.include,
+			ExcludeFiles: restoreOpts.files.exclude,
+			PageName:     restoreOpts.pageName,
+			Plugin:       restoreOpts.plugin,
+			Force:        restoreOpts.force,
+		}
+		client := newClient("")
+		if dryRun {
+			plan, err := client.PlanRestore(opts)
+			if err != nil {
+				fatalErr("生成还原计划失败", err)
+			}
+			reportPlan(plan)
+			return
+		}
+
 		// 还原图片
-		mode.RestoreImages(restoreOpts.input, restoreOpts.output, restoreOpts.aid, restoreOpts.concurrency)
+		results, skipped, err := client.Restore(cmd.Context(), opts)
+		if err != nil {
+			fatalErr("还原图片失败", err)
+		}
+		addRestoreItems(results)
+		reportSkipped(skipped)
 	},
 }
 
@@ -33,8 +66,14 @@ func init() {
 	// 本地标志
 	restoreCmd.Flags().StringVarP(&restoreOpts.input, "input", "i", "", "需要还原的图片文件夹路径（必传）")
 	restoreCmd.Flags().StringVarP(&restoreOpts.output, "output", "o", "", "还原后的图片输出文件夹路径（必传）")
-	restoreCmd.Flags().IntVarP(&restoreOpts.aid, "aid", "a", 0, "车牌号（必传）")
+	restoreCmd.Flags().VarP(&restoreOpts.aid, "aid", "a", "车牌号，支持数字、JM 编号和本子/章节链接（必传）")
 	restoreCmd.Flags().IntVarP(&restoreOpts.concurrency, "concurrency", "c", 8, "并发数（可选）")
+	restoreCmd.Flags().StringVar(&restoreOpts.plugin, "plugin", "", "提供切割方案的插件名（可选）")
+	addSelectionFlags(restoreCmd, &restoreOpts.selection)
+	addFileFilterFlags(restoreCmd, &restoreOpts.files)
+	addPageNameFlag(restoreCmd, &restoreOpts.pageName)
+	addDryRunFlag(restoreCmd)
+	restoreCmd.Flags().BoolVar(&restoreOpts.force, "force", false, "重新还原清单中已完成的图片，默认跳过（可选）")
 
 	// input、output、aid 这三个是必传的
 	requiredFlags := []string{"input", "output", "aid"}
@@ -1,17 +1,166 @@
 package cmd
 
 import (
+	"errors"
 	"github.com/spf13/cobra"
+	"net/http"
 	"os"
+	"pickit/internal/utils"
+	"pickit/pkg/pickit"
+	"strings"
+	"time"
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "pickit",
 	Short: "pickit 是一个命令行工具，提供了图片下载、还原、合成 PDF 的一些功能。",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		setupCommand(cmd)
+		loadSession()
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		exitWithReport()
+	},
 }
 
+var (
+	pluginDir   string // 插件目录
+	apiBase     string // 本子信息接口地址
+	sessionPath string // 登录状态文件
+)
+
+type logFlags struct {
+	level      string // 日志级别
+	format     string // 日志格式
+	file       string // 日志文件
+	maxSize    int    // 日志文件轮转大小（MB）
+	maxBackups int    // 保留的历史日志文件数量
+	redact     bool   // 隐藏地址和车牌号
+	quiet      bool   // 只输出错误日志
+	verbose    bool   // 输出调试日志
+}
+
+var logOpts logFlags
+
 func Execute() {
+	setupLocale(os.Args[1:])
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		// 未知命令、未知参数、缺少必传参数等
+		if cmdReport == nil {
+			cmdReport = &report{StartedAt: time.Now()}
+		}
+		cmdReport.Error = err.Error()
+		os.Exit(finishReport(exitUsage))
+	}
+}
+
+func init() {
+	// 全局标志
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "以 JSON 输出执行报告（可选）")
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", utils.DefaultConfigPath(), "配置文件，也可以通过 PICKIT_CONFIG 指定（可选）")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "使用配置文件中的 profile，也可以通过 PICKIT_PROFILE 指定（可选）")
+	rootCmd.PersistentFlags().StringVar(&pluginDir, "plugin-dir", utils.DefaultPluginDir(), "插件目录（可选）")
+	rootCmd.PersistentFlags().StringVar(&apiBase, "api", "", "本子信息接口地址，用于自动获取图片数量和章节（可选）")
+	rootCmd.PersistentFlags().StringVar(&sessionPath, "session", utils.DefaultSessionPath(), "登录状态文件（可选）")
+	rootCmd.PersistentFlags().StringVar(&lang, "lang", "", "界面语言: zh、en，默认根据 LC_ALL、LC_MESSAGES、LANG 环境变量选择（可选）")
+
+	// 日志标志，日志统一输出到标准错误或日志文件
+	rootCmd.PersistentFlags().StringVar(&logOpts.level, "log-level", "info", "日志级别: debug、info、warn、error（可选）")
+	rootCmd.PersistentFlags().StringVar(&logOpts.format, "log-format", utils.LogFormatConsole, "日志格式: console、json（可选）")
+	rootCmd.PersistentFlags().StringVar(&logOpts.file, "log-file", "", "日志写入该文件而不是标准错误，超过 --log-max-size 后轮转（可选）")
+	rootCmd.PersistentFlags().IntVar(&logOpts.maxSize, "log-max-size", 10, "日志文件轮转大小，单位 MB（可选）")
+	rootCmd.PersistentFlags().IntVar(&logOpts.maxBackups, "log-max-backups", 3, "保留的历史日志文件数量（可选）")
+	rootCmd.PersistentFlags().BoolVar(&logOpts.redact, "log-redact", false, "隐藏日志中的地址和车牌号，便于分享日志（可选）")
+	rootCmd.PersistentFlags().BoolVarP(&logOpts.quiet, "quiet", "q", false, "只输出错误日志，优先于 --log-level（可选）")
+	rootCmd.PersistentFlags().BoolVarP(&logOpts.verbose, "verbose", "v", false, "输出调试日志，优先于 --log-level（可选）")
+}
+
+// setupLogger 按日志参数重新配置日志器，需要在填充配置文件中的参数之后调用
+func setupLogger() {
+	if logOpts.quiet && logOpts.verbose {
+		fatalUsage("--quiet 和 --verbose 不能同时使用")
+	}
+	level := logOpts.level
+	switch {
+	case logOpts.quiet:
+		level = "error"
+	case logOpts.verbose:
+		level = "debug"
+	}
+
+	err := utils.ConfigureLogger(utils.LogOptions{
+		Level:      level,
+		Format:     logOpts.format,
+		File:       logOpts.file,
+		MaxSize:    logOpts.maxSize,
+		MaxBackups: logOpts.maxBackups,
+		Redact:     logOpts.redact,
+	})
+	if err != nil {
+		fatalUsage("日志参数无效", utils.Err(err))
+	}
+}
+
+// setupCommand 命令执行前的准备：开始记录报告，应用配置、语言和日志参数。
+// 子命令覆盖 PersistentPreRun 时也需要调用
+func setupCommand(cmd *cobra.Command) {
+	startReport(cmd)
+	applyConfig(cmd)
+	applyLocale()
+	setupLogger()
+}
+
+// loginSession 已加载的登录状态，没有登录或已过期时为 nil
+var loginSession *utils.Session
+
+// loadSession 加载已保存的登录状态，之后通过 newClient、newHTTPClient 创建的客户端都会携带该状态
+func loadSession() {
+	session, err := utils.LoadSession(sessionPath)
+	if errors.Is(err, utils.ErrSessionExpired) {
+		utils.LogWarn("登录已过期，请执行 pickit login 重新登录", utils.Str("username", session.Username))
+		return
+	}
+	if err != nil {
+		fatal("加载登录状态失败", utils.Err(err))
+	}
+	if session == nil {
+		return
+	}
+	loginSession = session
+	utils.LogDebug("已加载登录状态",
+		utils.Str("username", session.Username),
+		utils.Int("cookies", len(session.Cookies)))
+}
+
+// newClient 根据全局参数创建客户端，日志输出到全局日志器
+func newClient(proxy string) *pickit.Client {
+	return pickit.New(pickit.Options{
+		Proxy:     proxy,
+		Session:   loginSession,
+		Logger:    utils.Logger,
+		API:       apiBase,
+		PluginDir: pluginDir,
+	})
+}
+
+// newMetadataProvider 根据 --api 创建本子信息来源，未配置时返回 nil
+func newMetadataProvider(proxy string) utils.MetadataProvider {
+	if apiBase == "" {
+		return nil
+	}
+	return &utils.HTTPMetadataProvider{
+		BaseUrl: strings.TrimSuffix(apiBase, "/"),
+		Client:  newHTTPClient(proxy),
+	}
+}
+
+// newHTTPClient 创建下载和请求接口使用的 HTTP 客户端，携带已加载的登录状态
+func newHTTPClient(proxy string) *http.Client {
+	client := utils.NewHTTPClient(proxy, 15*time.Second)
+	if loginSession != nil {
+		if err := loginSession.Apply(client); err != nil {
+			fatal("加载登录状态失败", utils.Err(err))
+		}
 	}
+	return client
 }
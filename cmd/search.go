@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"path"
+	"pickit/internal/utils"
+	"pickit/pkg/pickit"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+type searchFlags struct {
+	tag         string // 标签
+	author      string // 作者
+	sort        string // 排序方式
+	page        int    // 页码
+	download    string // 需要下载的结果序号
+	cdn         string // 图片域名地址
+	output      string // 输出路径
+	concurrency int    // 并发数
+	proxy       string // 魔法
+}
+
+var searchOpts searchFlags
+
+var searchCmd = &cobra.Command{
+	Use:   "search <关键词>",
+	Short: "搜索本子",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if apiBase == "" {
+			fatalUsage("搜索需要通过 --api 指定接口地址")
+		}
+
+		query := utils.SearchQuery{
+			Tag:    searchOpts.tag,
+			Author: searchOpts.author,
+			Sort:   searchOpts.sort,
+			Page:   searchOpts.page,
+		}
+		if len(args) > 0 {
+			query.Query = args[0]
+		}
+		if query.Query == "" && query.Tag == "" && query.Author == "" {
+			fatalUsage("请传入关键词、--tag 或 --author")
+		}
+
+		// 提前校验下载参数，避免搜索完成后才报错
+		var selection utils.IntRanges
+		if searchOpts.download != "" {
+			var err error
+			selection, err = utils.ParseIntRanges(searchOpts.download)
+			if err != nil {
+				fatalUsage("下载序号无效", utils.Err(err))
+			}
+			if searchOpts.cdn == "" || searchOpts.output == "" {
+				fatalUsage("下载搜索结果需要 --cdn 和 --output")
+			}
+		}
+
+		client := newClient(searchOpts.proxy)
+		result, err := client.Search(cmd.Context(), query)
+		if err != nil {
+			fatalErr("搜索失败", err)
+		}
+
+		setData(result)
+		if !jsonOutput {
+			printSearchResult(result)
+		}
+
+		if selection == nil {
+			return
+		}
+		for i, item := range result.Items {
+			if !selection.Contains(i + 1) {
+				continue
+			}
+			opts := pickit.DownloadOptions{
+				Album:       utils.AlbumRef{Kind: utils.AlbumRefAlbum, Id: item.Id},
+				Cdn:         searchOpts.cdn,
+				Output:      path.Join(searchOpts.output, strconv.Itoa(item.Id)),
+				Concurrency: searchOpts.concurrency,
+			}
+			start := time.Now()
+			results, err := client.Download(cmd.Context(), opts)
+			if err != nil {
+				utils.LogError("下载搜索结果失败", utils.Int("aid", item.Id), utils.Err(err))
+			}
+			reportItem := albumItem(strconv.Itoa(item.Id), opts.Output, results, err)
+			reportItem.DurationMs = time.Since(start).Milliseconds()
+			addItem(reportItem)
+		}
+	},
+}
+
+// printSearchResult 以表格形式输出搜索结果
+func printSearchResult(result *utils.SearchResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, utils.T("#\tID\t标题\t页数\t标签"))
+	for i, item := range result.Items {
+		pages := "-"
+		if item.PageCount > 0 {
+			pages = strconv.Itoa(item.PageCount)
+		}
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\n", i+1, item.Id, item.Title, pages, strings.Join(item.Tags, ","))
+	}
+	_ = w.Flush()
+	fmt.Printf(utils.T("第 %d 页，共 %d 条结果\n"), result.Page, result.Total)
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+
+	// 本地标志
+	searchCmd.Flags().StringVarP(&searchOpts.tag, "tag", "t", "", "按标签筛选（可选）")
+	searchCmd.Flags().StringVar(&searchOpts.author, "author", "", "按作者筛选（可选）")
+	searchCmd.Flags().StringVarP(&searchOpts.sort, "sort", "s", "latest", "排序方式: latest、views、pages、likes（可选）")
+	searchCmd.Flags().IntVar(&searchOpts.page, "page", 1, "结果页码（可选）")
+	searchCmd.Flags().StringVarP(&searchOpts.download, "download", "d", "", "下载指定序号的结果，如 1,3-5（可选）")
+	searchCmd.Flags().StringVarP(&searchOpts.cdn, "cdn", "u", "", "图片 cdn 域名，下载时必传（可选）")
+	searchCmd.Flags().StringVarP(&searchOpts.output, "output", "o", "", "保存文件夹路径，每个本子保存到 <output>/<车牌号>，下载时必传（可选）")
+	searchCmd.Flags().IntVarP(&searchOpts.concurrency, "concurrency", "c", 8, "并发数（可选）")
+	searchCmd.Flags().StringVarP(&searchOpts.proxy, "proxy", "p", "", "魔法（可选）")
+}
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// selectionFlags 页面选择标志
+type selectionFlags struct {
+	pages   string // 包含的页面
+	exclude string // 排除的页面
+}
+
+// addSelectionFlags 为命令添加 --pages 和 --exclude
+func addSelectionFlags(cmd *cobra.Command, flags *selectionFlags) {
+	cmd.Flags().StringVar(&flags.pages, "pages", "", "需要处理的页面，如 1-20,25,-3,ch2:1-5，负数表示倒数（可选）")
+	cmd.Flags().StringVar(&flags.exclude, "exclude", "", "需要跳过的页面，格式同 --pages（可选）")
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"log"
+	"os"
+	"pickit/internal/utils"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+type subscribeFlags struct {
+	output      string // 输出路径
+	cdn         string // 图片域名地址
+	proxy       string // 魔法
+	concurrency int    // 并发数
+	pdf         bool   // 同步后合成 PDF
+	password    string // PDF 密码
+	fromNow     bool   // 只同步之后发布的章节
+}
+
+var (
+	subscribeOpts    subscribeFlags
+	subscriptionPath string // 订阅文件
+)
+
+var subscribeCmd = &cobra.Command{
+	Use:   "subscribe",
+	Short: "管理订阅的本子",
+}
+
+var subscribeAddCmd = &cobra.Command{
+	Use:   "add <车牌号>",
+	Short: "订阅本子，sync 时自动下载新章节",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ref, err := utils.ParseAlbumRef(args[0])
+		if err != nil {
+			fatalUsage("车牌号无效", utils.Err(err))
+		}
+		if ref.Kind != utils.AlbumRefAlbum {
+			fatalUsage("只能订阅本子，不能订阅单个章节")
+		}
+
+		store := loadSubscriptions()
+		sub := utils.Subscription{
+			Aid:         ref.Id,
+			Output:      subscribeOpts.output,
+			Cdn:         subscribeOpts.cdn,
+			Proxy:       subscribeOpts.proxy,
+			Concurrency: subscribeOpts.concurrency,
+			Pdf:         subscribeOpts.pdf,
+			Password:    subscribeOpts.password,
+			Chapters:    []int{},
+		}
+		if existing := store.Get(ref.Id); existing != nil {
+			// 保留已同步的章节
+			sub.Title, sub.Chapters, sub.LastSync = existing.Title, existing.Chapters, existing.LastSync
+		}
+
+		if subscribeOpts.fromNow {
+			provider := newMetadataProvider(subscribeOpts.proxy)
+			if provider == nil {
+				fatalUsage("--from-now 需要通过 --api 指定接口地址")
+			}
+			album, err := provider.Album(cmd.Context(), ref.Id)
+			if err != nil {
+				fatal("获取本子信息失败", utils.Err(err))
+			}
+			sub.Title = album.Title
+			for _, chapter := range album.Chapters {
+				if !sub.HasChapter(chapter.Id) {
+					sub.Chapters = append(sub.Chapters, chapter.Id)
+				}
+			}
+		}
+
+		store.Put(sub)
+		if err := store.Save(); err != nil {
+			fatal("保存订阅失败", utils.Err(err))
+		}
+		utils.LogInfo("订阅成功", utils.Int("aid", sub.Aid), utils.Int("known_chapters", len(sub.Chapters)))
+		setData(sub)
+	},
+}
+
+var subscribeListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "列出订阅的本子",
+	Run: func(cmd *cobra.Command, args []string) {
+		store := loadSubscriptions()
+		setData(store.Subscriptions)
+		if jsonOutput {
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, utils.T("车牌号\t标题\t已同步章节\t上次同步\t输出目录"))
+		for _, sub := range store.Subscriptions {
+			lastSync := "-"
+			if !sub.LastSync.IsZero() {
+				lastSync = sub.LastSync.Local().Format(time.DateTime)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\n", sub.Aid, sub.Title, len(sub.Chapters), lastSync, sub.Output)
+		}
+		_ = w.Flush()
+	},
+}
+
+var subscribeRemoveCmd = &cobra.Command{
+	Use:   "remove <车牌号>...",
+	Short: "取消订阅",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store := loadSubscriptions()
+		for _, aid := range parseAids(args) {
+			if !store.Remove(aid) {
+				utils.LogWarn("没有订阅该本子", utils.Int("aid", aid))
+				addItem(reportItem{Input: strconv.Itoa(aid), Status: statusFailed, Error: utils.T("没有订阅该本子")})
+				continue
+			}
+			addItem(reportItem{Input: strconv.Itoa(aid), Status: statusOK})
+		}
+		if err := store.Save(); err != nil {
+			fatal("保存订阅失败", utils.Err(err))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(subscribeCmd)
+	subscribeCmd.AddCommand(subscribeAddCmd, subscribeListCmd, subscribeRemoveCmd)
+
+	subscribeCmd.PersistentFlags().StringVar(&subscriptionPath, "subscriptions", utils.DefaultSubscriptionPath(), "订阅文件（可选）")
+
+	// 本地标志
+	subscribeAddCmd.Flags().StringVarP(&subscribeOpts.output, "output", "o", "", "保存文件夹路径（必传）")
+	subscribeAddCmd.Flags().StringVarP(&subscribeOpts.cdn, "cdn", "u", "", "图片 cdn 域名（必传）")
+	subscribeAddCmd.Flags().StringVarP(&subscribeOpts.proxy, "proxy", "p", "", "魔法（可选）")
+	subscribeAddCmd.Flags().IntVarP(&subscribeOpts.concurrency, "concurrency", "c", 8, "并发数（可选）")
+	subscribeAddCmd.Flags().BoolVar(&subscribeOpts.pdf, "pdf", false, "同步后重新合成 PDF（可选）")
+	subscribeAddCmd.Flags().StringVar(&subscribeOpts.password, "password", "", "PDF 密码（可选）")
+	subscribeAddCmd.Flags().BoolVar(&subscribeOpts.fromNow, "from-now", false, "把当前已有的章节视为已同步，只同步之后发布的章节，需要 --api（可选）")
+
+	// output、cdn 这两个是必传的
+	requiredFlags := []string{"output", "cdn"}
+	for _, flag := range requiredFlags {
+		if err := subscribeAddCmd.MarkFlagRequired(flag); err != nil {
+			log.Fatalf("初始化失败: 无法标记 %s 为必需参数: %v", flag, err)
+		}
+	}
+}
+
+// loadSubscriptions 读取订阅列表
+func loadSubscriptions() *utils.SubscriptionStore {
+	store, err := utils.LoadSubscriptions(subscriptionPath)
+	if err != nil {
+		fatal("读取订阅失败", utils.Err(err))
+	}
+	return store
+}
+
+// parseAids 解析多个车牌号
+func parseAids(args []string) []int {
+	aids := make([]int, 0, len(args))
+	for _, arg := range args {
+		ref, err := utils.ParseAlbumRef(arg)
+		if err != nil {
+			fatalUsage("车牌号无效", utils.Str("aid", strings.TrimSpace(arg)), utils.Err(err))
+		}
+		aids = append(aids, ref.Id)
+	}
+	return aids
+}
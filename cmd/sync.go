@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"context"
+	"github.com/spf13/cobra"
+	"os"
+	"os/signal"
+	"pickit/internal/mode"
+	"pickit/internal/utils"
+	"pickit/pkg/pickit"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+type syncFlags struct {
+	watch    bool          // 持续运行
+	interval time.Duration // 同步间隔
+}
+
+var syncOpts syncFlags
+
+var syncCmd = &cobra.Command{
+	Use:   "sync [车牌号]...",
+	Short: "同步订阅，下载并还原新发布的章节",
+	Run: func(cmd *cobra.Command, args []string) {
+		aids := parseAids(args)
+		if dryRun {
+			if syncOpts.watch {
+				fatalUsage("--dry-run 不能与 --watch 同时使用")
+			}
+			planSubscriptions(cmd.Context(), aids)
+			return
+		}
+		if !syncOpts.watch {
+			syncSubscriptions(cmd.Context(), aids)
+			return
+		}
+
+		if syncOpts.interval <= 0 {
+			fatalUsage("同步间隔必须大于 0")
+		}
+
+		// 收到退出信号时取消正在进行的同步
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		utils.LogInfo("进入持续同步模式", utils.Float64("interval_seconds", syncOpts.interval.Seconds()))
+		for {
+			syncSubscriptions(ctx, aids)
+
+			select {
+			case <-ctx.Done():
+				utils.LogInfo("收到退出信号，停止同步")
+				return
+			case <-time.After(syncOpts.interval):
+			}
+		}
+	},
+}
+
+// syncTargets 需要同步的车牌号，aids 为空时为全部订阅
+func syncTargets(store *utils.SubscriptionStore, aids []int) []int {
+	targets := aids
+	if len(targets) == 0 {
+		for _, sub := range store.Subscriptions {
+			targets = append(targets, sub.Aid)
+		}
+	}
+	if len(targets) == 0 {
+		utils.LogWarn("没有订阅任何本子")
+	}
+	return targets
+}
+
+// planSubscriptions 生成同步订阅的计划，不下载也不修改订阅
+func planSubscriptions(ctx context.Context, aids []int) {
+	store := loadSubscriptions()
+	plan := &pickit.Plan{}
+	for _, aid := range syncTargets(store, aids) {
+		sub := store.Get(aid)
+		if sub == nil {
+			plan.Items = append(plan.Items, pickit.PlanItem{Stage: utils.StageDownload, Input: strconv.Itoa(aid), Size: -1, Err: utils.Errorf("没有订阅该本子")})
+			continue
+		}
+		plan.Merge(mode.PlanSync(ctx, sub, newMetadataProvider(sub.Proxy), newHTTPClient(sub.Proxy)))
+	}
+	reportPlan(plan)
+}
+
+// syncSubscriptions 同步一轮订阅，aids 为空时同步全部订阅
+func syncSubscriptions(ctx context.Context, aids []int) {
+	store := loadSubscriptions()
+	targets := syncTargets(store, aids)
+	if len(targets) == 0 {
+		return
+	}
+
+	total := 0
+	for _, aid := range targets {
+		sub := store.Get(aid)
+		if sub == nil {
+			utils.LogWarn("没有订阅该本子", utils.Int("aid", aid))
+			addItem(reportItem{Input: strconv.Itoa(aid), Status: statusFailed, Error: utils.T("没有订阅该本子")})
+			continue
+		}
+
+		start := time.Now()
+		synced, err := mode.SyncSubscription(ctx, sub, newMetadataProvider(sub.Proxy), newHTTPClient(sub.Proxy))
+		if err != nil {
+			utils.LogError("同步订阅失败", utils.Int("aid", aid), utils.Err(err))
+		}
+		total += synced
+		addItem(reportItem{
+			Input:      strconv.Itoa(aid),
+			Output:     sub.Output,
+			Status:     errStatus(err),
+			Error:      errString(err),
+			DurationMs: time.Since(start).Milliseconds(),
+			Chapters:   synced,
+		})
+
+		// 每个本子同步后立即保存，避免中途退出丢失进度
+		if err := store.Save(); err != nil {
+			utils.LogError("保存订阅失败", utils.Err(err))
+		}
+	}
+
+	utils.LogInfo("本轮同步完成",
+		utils.Int("subscriptions", len(targets)),
+		utils.Int("new_chapters", total))
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+
+	syncCmd.Flags().StringVar(&subscriptionPath, "subscriptions", utils.DefaultSubscriptionPath(), "订阅文件（可选）")
+	syncCmd.Flags().BoolVarP(&syncOpts.watch, "watch", "w", false, "持续运行，按 --interval 定期同步（可选）")
+	syncCmd.Flags().DurationVar(&syncOpts.interval, "interval", 6*time.Hour, "持续运行时的同步间隔（可选）")
+	addDryRunFlag(syncCmd)
+}
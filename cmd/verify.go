@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"pickit/internal/mode"
+	"pickit/internal/utils"
+	"text/tabwriter"
+)
+
+type verifyFlags struct {
+	refetch     bool   // 重新下载有问题的原图
+	restore     bool   // 重新还原有问题的图片
+	concurrency int    // 并发数
+	proxy       string // 魔法
+}
+
+var verifyOpts verifyFlags
+
+// verifyReport 校验结果，--json 时写入报告的 data
+type verifyReport struct {
+	Manifests int                 `json:"manifests"`
+	Checked   int                 `json:"checked"`
+	Issues    []verifyReportIssue `json:"issues"`
+}
+
+type verifyReportIssue struct {
+	Problem     string `json:"problem"` // missing、gap、hash_mismatch、corrupt
+	Stage       string `json:"stage"`
+	Path        string `json:"path"`
+	Source      string `json:"source,omitempty"`
+	Page        int    `json:"page,omitempty"` // 缺少的页码
+	Error       string `json:"error,omitempty"`
+	Repaired    bool   `json:"repaired"`
+	RepairError string `json:"repair_error,omitempty"`
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify <目录>",
+	Short: "按清单检查目录中的文件是否完整，可以只重新下载或还原有问题的文件",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		result, err := mode.Verify(cmd.Context(), args[0], mode.VerifyOptions{
+			Refetch:     verifyOpts.refetch,
+			Restore:     verifyOpts.restore,
+			Concurrency: verifyOpts.concurrency,
+			Proxy:       verifyOpts.proxy,
+			Client:      newHTTPClient(verifyOpts.proxy),
+		})
+		if err != nil {
+			fatal("校验失败", utils.Err(err))
+		}
+
+		data := verifyReport{Manifests: result.Manifests, Checked: result.Checked, Issues: make([]verifyReportIssue, 0, len(result.Issues))}
+		for _, issue := range result.Issues {
+			data.Issues = append(data.Issues, verifyReportIssue{
+				Problem:     issue.Problem,
+				Stage:       issue.Stage,
+				Path:        issue.Path,
+				Source:      issue.Source,
+				Page:        issue.Page,
+				Error:       errString(issue.Err),
+				Repaired:    issue.Repaired,
+				RepairError: errString(issue.RepairErr),
+			})
+			status := statusFailed
+			if issue.Repaired {
+				status = statusOK
+			}
+			addItem(reportItem{Input: issue.Source, Output: issue.Path, Status: status, Error: errString(issue.Err), Kind: utils.ErrorKind(issue.Err)})
+		}
+		setData(data)
+		if !jsonOutput {
+			printVerifyResult(result)
+		}
+	},
+}
+
+// printVerifyResult 以表格输出发现的问题，未修复的问题在表格后给出处理方法
+func printVerifyResult(result *mode.VerifyResult) {
+	if len(result.Issues) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, utils.T("问题\t阶段\t文件\t说明"))
+		for _, issue := range result.Issues {
+			note := errString(issue.Err)
+			switch {
+			case issue.Repaired:
+				note = utils.T("已修复")
+			case issue.RepairErr != nil:
+				note = fmt.Sprintf(utils.T("修复失败: %v"), issue.RepairErr)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", issue.Problem, issue.Stage, issue.Path, note)
+		}
+		_ = w.Flush()
+	}
+	fmt.Printf(utils.T("检查 %d 个清单中的 %d 个文件，发现 %d 个问题，已修复 %d 个\n"),
+		result.Manifests, result.Checked, len(result.Issues), result.Repaired())
+
+	// 提示尚未尝试的修复方式
+	refetch, restore, gap := false, false, false
+	for _, issue := range result.Issues {
+		if issue.Repaired || issue.RepairErr != nil {
+			continue
+		}
+		switch {
+		case issue.Problem == utils.IssueGap:
+			gap = true
+		case issue.Stage == utils.StageDownload:
+			refetch = true
+		case issue.Stage == utils.StageRestore:
+			restore = true
+		}
+	}
+	if refetch && !verifyOpts.refetch {
+		fmt.Println(utils.T("使用 --refetch 重新下载有问题的原图"))
+	}
+	if restore && !verifyOpts.restore {
+		fmt.Println(utils.T("使用 --restore 重新还原有问题的图片"))
+	}
+	if gap {
+		fmt.Println(utils.T("缺少的页面没有记录在清单中，无法自动修复，需要用 download 或 restore 的 --pages 重新处理这些页面"))
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+
+	verifyCmd.Flags().BoolVar(&verifyOpts.refetch, "refetch", false, "按清单中的地址重新下载缺失、损坏或内容不一致的原图（可选）")
+	verifyCmd.Flags().BoolVar(&verifyOpts.restore, "restore", false, "按清单中的原图和切割刀数重新还原有问题的图片（可选）")
+	verifyCmd.Flags().IntVarP(&verifyOpts.concurrency, "concurrency", "c", 8, "并发数（可选）")
+	verifyCmd.Flags().StringVarP(&verifyOpts.proxy, "proxy", "p", "", "魔法，重新下载时使用（可选）")
+}
@@ -0,0 +1,186 @@
+package mode
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"pickit/internal/utils"
+	"strconv"
+)
+
+// BatchJobResult 批量任务中单个本子的处理结果
+type BatchJobResult struct {
+	Input  string // 任务文件中填写的车牌号
+	Aid    int
+	Export string
+	Output string
+	Pages  int   // 计划下载的页数
+	Failed int   // 下载或还原失败的页数
+	Err    error // 导致整个本子失败的错误
+	// PageErrors 失败页面的错误，与 Err 一样可以用 errors.Is 和 errors.As 判断类别
+	PageErrors []error
+}
+
+// Status 处理状态: ok、partial、failed
+func (r BatchJobResult) Status() string {
+	switch {
+	case r.Err != nil:
+		return "failed"
+	case r.Failed > 0:
+		return "partial"
+	default:
+		return "ok"
+	}
+}
+
+// batchUnit 本子中需要下载的一个章节
+type batchUnit struct {
+	chapter   int
+	aid       int
+	count     int
+	rawDir    string
+	imagesDir string
+}
+
+// RunBatch 执行批量任务。每个本子对应任务列表中的一个任务，相同车牌号和输出路径的未完成任务会被继续执行，
+// 已完成的页面不会重复处理。export 为 raw 时下载到 <output>[/<章节序号>]，否则原图保存在 <output>/raw、
+// 还原后的图片保存在 <output>/images，export 为 pdf 时再合成 <output>/<车牌号>.pdf。
+func RunBatch(ctx context.Context, file *utils.BatchFile, store *utils.JobStore, provider utils.MetadataProvider, concurrency int, client *http.Client) []BatchJobResult {
+	jobs := make([]*utils.Job, len(file.Albums))
+	for i, album := range file.Albums {
+		job, err := batchJob(ctx, store, album)
+		if err != nil {
+			// 无法保存任务时仍然执行，只是中断后不能继续
+			utils.LoggerFrom(ctx).Error("创建任务失败", utils.Str("aid", album.Aid), utils.Err(err))
+		}
+		if job == nil {
+			job = newBatchJob(album)
+		}
+		jobs[i] = job
+	}
+
+	RunJobs(ctx, store, jobs, provider, concurrency, client)
+
+	results := make([]BatchJobResult, len(jobs))
+	for i, job := range jobs {
+		results[i] = JobResult(job)
+	}
+	return results
+}
+
+// JobResult 把任务转换为处理结果
+func JobResult(job *utils.Job) BatchJobResult {
+	r := BatchJobResult{
+		Input:  job.Input,
+		Aid:    job.Aid,
+		Export: job.Export,
+		Output: job.Output,
+		Pages:  len(job.Pages),
+		Failed: len(job.Pages) - job.Progress(),
+	}
+	r.Err = job.Err()
+	for i := range job.Pages {
+		if err := job.Pages[i].Err(); err != nil {
+			r.PageErrors = append(r.PageErrors, err)
+		}
+	}
+	return r
+}
+
+// findBatchJob 查找本子对应的可以继续执行的任务，没有时返回 nil
+func findBatchJob(store *utils.JobStore, album utils.BatchAlbum) (*utils.Job, error) {
+	existing, err := store.FindUnfinished(album.Aid, album.Output)
+	if err != nil || existing == nil {
+		return nil, err
+	}
+	if existing.Cdn == album.Cdn && existing.Export == album.Export &&
+		existing.Chapters == album.Chapters && existing.Count == album.Count {
+		return existing, nil
+	}
+	return nil, nil
+}
+
+// newBatchJob 根据任务文件中的本子创建任务，不保存
+func newBatchJob(album utils.BatchAlbum) *utils.Job {
+	job := &utils.Job{
+		Input:    album.Aid,
+		Cdn:      album.Cdn,
+		Count:    album.Count,
+		Chapters: album.Chapters,
+		Output:   album.Output,
+		Export:   album.Export,
+		Password: album.Password,
+	}
+	if ref, err := utils.ParseAlbumRef(album.Aid); err == nil {
+		job.Aid = ref.Id
+	}
+	return job
+}
+
+// batchJob 查找可以继续执行的任务，没有或无法读取已有任务时创建新任务，返回的任务不会为 nil
+func batchJob(ctx context.Context, store *utils.JobStore, album utils.BatchAlbum) (*utils.Job, error) {
+	logger := utils.LoggerFrom(ctx)
+	existing, err := findBatchJob(store, album)
+	if err != nil {
+		// 无法读取任务目录时从头执行，不影响其他本子
+		logger.Warn("查找未完成的任务失败", utils.Str("aid", album.Aid), utils.Err(err))
+	}
+	if existing != nil {
+		logger.Info("继续执行未完成的任务",
+			utils.Str("job", existing.Id),
+			utils.Str("aid", album.Aid),
+			utils.Int("done", existing.Progress()),
+			utils.Int("pages", len(existing.Pages)))
+		existing.Password = album.Password
+		return existing, nil
+	}
+
+	job := newBatchJob(album)
+	return job, store.NewJob(job)
+}
+
+// resolveBatchUnits 确定单个本子需要下载的章节及其目录，返回车牌号和章节列表
+func resolveBatchUnits(ctx context.Context, album utils.BatchAlbum, provider utils.MetadataProvider) (int, []batchUnit, error) {
+	ref, err := utils.ParseAlbumRef(album.Aid)
+	if err != nil {
+		return 0, nil, err
+	}
+	if album.Output == "" {
+		return 0, nil, utils.Errorf("没有填写输出路径")
+	}
+	if album.Cdn == "" {
+		return 0, nil, utils.Errorf("没有填写图片 cdn 域名")
+	}
+
+	rawDir, imagesDir := album.Output, ""
+	if album.Export != utils.ExportRaw {
+		rawDir, imagesDir = path.Join(album.Output, "raw"), path.Join(album.Output, "images")
+	}
+
+	var selection utils.IntRanges
+	if album.Chapters != "" {
+		if selection, err = utils.ParseIntRanges(album.Chapters); err != nil {
+			return 0, nil, err
+		}
+	}
+	if album.Count > 0 && selection == nil {
+		return ref.Id, []batchUnit{{chapter: 1, aid: ref.Id, count: album.Count, rawDir: rawDir, imagesDir: imagesDir}}, nil
+	}
+
+	chapters, multi, err := ResolveChapters(ctx, provider, ref, selection)
+	if err != nil {
+		return 0, nil, err
+	}
+	units := make([]batchUnit, 0, len(chapters))
+	for _, chapter := range chapters {
+		unit := batchUnit{chapter: chapter.Sort, aid: chapter.Id, count: chapter.PageCount, rawDir: rawDir, imagesDir: imagesDir}
+		if multi {
+			unit.rawDir = path.Join(rawDir, strconv.Itoa(chapter.Sort))
+			if imagesDir != "" {
+				unit.imagesDir = path.Join(imagesDir, strconv.Itoa(chapter.Sort))
+			}
+		}
+		units = append(units, unit)
+	}
+	return ref.Id, units, nil
+}
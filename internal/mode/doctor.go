@@ -0,0 +1,88 @@
+package mode
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"pickit/internal/utils"
+	"time"
+)
+
+// DoctorOptions 诊断选项，CDN 相关的选项与下载相同
+type DoctorOptions struct {
+	Cdn          string             // 图片域名地址
+	Aid          int                // 示例页面的车牌号，为 0 时只检查连接，不检查状态码
+	Proxy        string             // 魔法
+	Client       *http.Client       // 请求示例页面使用的 HTTP 客户端，为 nil 时按 Proxy 创建
+	UrlTemplate  *utils.UrlTemplate // 图片地址模板
+	Ext          string             // 图片扩展名
+	FallbackExts []string           // 404 时依次尝试的备选扩展名
+	Output       string             // 输出目录
+	MinFree      int64              // 输出目录所在磁盘至少需要的剩余空间（字节）
+}
+
+// Diagnose 依次检查代理、DNS、TLS、示例页面和输出目录，返回每一项的结果。
+// 请求示例页面时使用与下载相同的 HTTP 客户端设置
+func Diagnose(ctx context.Context, opts DoctorOptions) ([]utils.CheckResult, error) {
+	cdn, err := url.Parse(opts.Cdn)
+	if err != nil || cdn.Host == "" {
+		if err == nil {
+			err = utils.Errorf("缺少协议或主机名: %s", opts.Cdn)
+		}
+		return nil, utils.Errorf("CDN 地址无效: %w", err)
+	}
+
+	results := make([]utils.CheckResult, 0, 6)
+	proxyRes := utils.CheckProxyReachable(ctx, opts.Proxy)
+	dnsRes := utils.CheckDNSResolve(ctx, cdn.Hostname(), opts.Proxy != "")
+	results = append(results, proxyRes, dnsRes)
+
+	client := opts.Client
+	if client == nil {
+		client = utils.NewHTTPClient(opts.Proxy, 15*time.Second)
+	}
+	if opts.Aid > 0 {
+		page := opts.UrlTemplate.Render(opts.Cdn, opts.Aid, 1, opts.Ext)
+		fallbacks := make([]string, 0, len(opts.FallbackExts))
+		for _, ext := range opts.FallbackExts {
+			if ext != "" && ext != opts.Ext {
+				fallbacks = append(fallbacks, opts.UrlTemplate.Render(opts.Cdn, opts.Aid, 1, ext))
+			}
+		}
+		tlsRes, httpRes := utils.CheckPage(ctx, client, page, fallbacks...)
+		results = append(results, tlsRes, httpRes)
+	} else {
+		// 只要服务端有响应就说明可以连接，状态码不代表图片能否下载
+		tlsRes, httpRes := utils.CheckPage(ctx, client, opts.Cdn)
+		var httpErr *utils.HTTPError
+		if !httpRes.Failed() || errors.As(httpRes.Err, &httpErr) {
+			httpRes.Status, httpRes.Err, httpRes.Hint = utils.CheckPass, nil, ""
+			httpRes.Detail = utils.T("已连接，没有传入车牌号，不检查示例页面")
+		}
+		results = append(results, tlsRes, httpRes)
+	}
+
+	// 代理或域名解析有问题时请求必然失败，建议先解决前面的问题
+	if http := &results[len(results)-1]; http.Failed() && http.Detail == "" {
+		for _, res := range []utils.CheckResult{proxyRes, dnsRes} {
+			if res.Failed() {
+				http.Hint = res.Hint
+				break
+			}
+		}
+	}
+
+	results = append(results, utils.CheckDirWritable(opts.Output))
+	results = append(results, utils.CheckDiskFree(opts.Output, opts.MinFree))
+
+	for _, res := range results {
+		if res.Failed() {
+			utils.LoggerFrom(ctx).Debug("诊断未通过",
+				utils.Str("check", res.Name),
+				utils.Str("target", res.Target),
+				utils.Err(res.Err))
+		}
+	}
+	return results, nil
+}
@@ -1,23 +1,222 @@
 package mode
 
 import (
+	"context"
+	"net/http"
+	"os"
 	"path"
 	"path/filepath"
 	"pickit/internal/utils"
 )
 
-func DownloadAlbum(cdn, output, proxy string, aid, count, concurrency int) {
+type DownloadOptions struct {
+	Cdn          string               // 图片域名地址
+	Output       string               // 输出路径
+	Proxy        string               // 魔法
+	Client       *http.Client         // 下载图片使用的 HTTP 客户端，为 nil 时按 Proxy 创建
+	Aid          int                  // 车牌号
+	Count        int                  // 图片数量
+	Concurrency  int                  // 并发数
+	UrlTemplate  *utils.UrlTemplate   // 图片地址模板
+	Ext          string               // 图片扩展名
+	FallbackExts []string             // 404 时依次尝试的备选扩展名
+	Selection    *utils.PageSelection // 页面选择
+	Plugin       *utils.Plugin        // 插件
+	Naming       *utils.Naming        // 目录和文件命名模板，为 nil 时使用默认结构
+	Title        string               // 本子标题，用于 {title} 占位符
+	Events       utils.EventHandler   // 下载进度事件，可以为 nil
+	Force        bool                 // 重新下载清单中已完成的页面
+}
+
+// DownloadRef 下载本子或章节，返回每一页的下载结果。opts.Count 为 0 或选择了章节时，通过本子信息确定需要下载的章节和图片数量
+func DownloadRef(ctx context.Context, opts DownloadOptions, provider utils.MetadataProvider, ref utils.AlbumRef, chapterSelection utils.IntRanges) ([]utils.BatchDownloadResult, error) {
+	task, err := refDownloadTasks(ctx, opts, provider, ref, chapterSelection)
+	if err != nil {
+		return nil, err
+	}
+	return runDownloadTasks(ctx, opts, task)
+}
+
+// refDownloadTasks 确定本子或章节需要下载的页面，不创建任何目录
+func refDownloadTasks(ctx context.Context, opts DownloadOptions, provider utils.MetadataProvider, ref utils.AlbumRef, chapterSelection utils.IntRanges) ([]utils.DownloadTask, error) {
+	opts.Aid = ref.Id
+	if opts.Count > 0 && chapterSelection == nil {
+		return albumDownloadTasks(ctx, opts)
+	}
+
+	album, chapters, multi, err := ResolveAlbum(ctx, provider, ref, chapterSelection)
+	if err != nil {
+		return nil, err
+	}
+	opts.Title = album.Title
+	if multi {
+		return chapterDownloadTasks(ctx, opts, chapters)
+	}
+
+	opts.Aid, opts.Count = chapters[0].Id, chapters[0].PageCount
+	return albumDownloadTasks(ctx, opts)
+}
+
+func DownloadAlbum(ctx context.Context, opts DownloadOptions) ([]utils.BatchDownloadResult, error) {
+	task, err := albumDownloadTasks(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return runDownloadTasks(ctx, opts, task)
+}
+
+// albumDownloadTasks 构建单章节本子的下载任务
+func albumDownloadTasks(ctx context.Context, opts DownloadOptions) ([]utils.DownloadTask, error) {
+	dir := opts.Naming.AlbumPath(opts.Output, utils.NameValues{Aid: opts.Aid, Title: opts.Title, Chapter: 1})
+	return buildDownloadTasks(ctx, opts, 1, opts.Aid, opts.Count, dir)
+}
+
+// DownloadChapters 下载多章节本子，每个章节保存到章节目录（默认为 <output>/<章节序号>/）中，所有章节共用一个下载协程池
+func DownloadChapters(ctx context.Context, opts DownloadOptions, chapters []utils.ChapterMeta) ([]utils.BatchDownloadResult, error) {
+	task, err := chapterDownloadTasks(ctx, opts, chapters)
+	if err != nil {
+		return nil, err
+	}
+	return runDownloadTasks(ctx, opts, task)
+}
+
+// chapterDownloadTasks 构建多章节本子的下载任务
+func chapterDownloadTasks(ctx context.Context, opts DownloadOptions, chapters []utils.ChapterMeta) ([]utils.DownloadTask, error) {
+	logger := utils.LoggerFrom(ctx)
+	albumDir := opts.Naming.AlbumPath(opts.Output, utils.NameValues{Aid: opts.Aid, Title: opts.Title})
+	task := make([]utils.DownloadTask, 0)
+	for _, chapter := range chapters {
+		dir := opts.Naming.ChapterPath(albumDir, utils.NameValues{Aid: opts.Aid, Title: opts.Title, Chapter: chapter.Sort})
+		logger.Info("添加章节下载任务",
+			utils.Int("chapter", chapter.Sort),
+			utils.Int("id", chapter.Id),
+			utils.Str("title", chapter.Title),
+			utils.Int("count", chapter.PageCount),
+			utils.Str("output", dir))
+		chapterTask, err := buildDownloadTasks(ctx, opts, chapter.Sort, chapter.Id, chapter.PageCount, dir)
+		if err != nil {
+			return nil, err
+		}
+		task = append(task, chapterTask...)
+	}
+	return task, nil
+}
+
+// buildDownloadTasks 构建单个章节的下载任务，chapter 为章节序号，用于页面选择。只确定下载地址和保存路径，不创建目录
+func buildDownloadTasks(ctx context.Context, opts DownloadOptions, chapter, aid, count int, output string) ([]utils.DownloadTask, error) {
 	// 构建下载 url 切片
-	urls := utils.ImageUrlBuilder(aid, cdn, count)
+	var urls []string
+	if usePluginUrls(opts.Plugin) {
+		// 由插件提供下载地址
+		pluginUrls, err := opts.Plugin.Urls(ctx, aid, opts.Cdn, count)
+		if err != nil {
+			return nil, utils.Errorf("插件获取下载地址失败: %w", err)
+		}
+		urls = pluginUrls
+	} else if opts.UrlTemplate != nil {
+		urls = opts.UrlTemplate.Build(opts.Cdn, aid, count, opts.Ext)
+	} else {
+		urls = utils.ImageUrlBuilder(aid, opts.Cdn, count)
+	}
 
 	// 构建下载任务
-	task := make([]utils.DownloadTask, len(urls))
+	task := make([]utils.DownloadTask, 0, len(urls))
 	for i, url := range urls {
-		task[i] = utils.DownloadTask{
+		if !opts.Selection.Contains(chapter, i+1, len(urls)) {
+			continue
+		}
+		t := utils.DownloadTask{
 			Url:  url,
-			Dist: path.Join(output, filepath.Base(url)),
+			Dist: path.Join(output, pageFilename(opts, i+1, url, opts.Ext)),
+		}
+
+		// 备选扩展名
+		for _, ext := range opts.FallbackExts {
+			var fallbackUrl string
+			if opts.UrlTemplate != nil && !usePluginUrls(opts.Plugin) {
+				fallbackUrl = opts.UrlTemplate.Render(opts.Cdn, aid, i+1, ext)
+			} else {
+				fallbackUrl = utils.ReplaceUrlExt(url, ext)
+			}
+			if fallbackUrl == url {
+				continue
+			}
+			t.Fallbacks = append(t.Fallbacks, utils.DownloadTask{
+				Url:  fallbackUrl,
+				Dist: path.Join(output, pageFilename(opts, i+1, fallbackUrl, ext)),
+			})
+		}
+		task = append(task, t)
+	}
+
+	if opts.Selection != nil {
+		utils.LoggerFrom(ctx).Info("页面选择完成",
+			utils.Int("chapter", chapter),
+			utils.Int("total", len(urls)),
+			utils.Int("selected", len(task)))
+	}
+	return task, nil
+}
+
+// pageFilename 页面的原文件名。使用地址模板时由模板中 {page} 渲染的页码和扩展名组成，
+// 避免地址最后一段不包含页码（如 {page}/img.{ext} 或页码在查询参数中）时不同页面重名；否则取下载地址中的文件名。
+// 还原时按原文件名计算切割刀数，所以原图不使用页面文件名模板
+func pageFilename(opts DownloadOptions, page int, url, ext string) string {
+	if opts.UrlTemplate != nil && !usePluginUrls(opts.Plugin) {
+		return utils.SanitizeFilename(opts.UrlTemplate.Filename(page, ext))
+	}
+	return utils.SanitizeFilename(utils.FilenameFromUrl(url))
+}
+
+// runDownloadTasks 创建保存目录后执行下载任务
+func runDownloadTasks(ctx context.Context, opts DownloadOptions, task []utils.DownloadTask) ([]utils.BatchDownloadResult, error) {
+	dirs := make(map[string]bool)
+	for _, t := range task {
+		for _, candidate := range append([]utils.DownloadTask{t}, t.Fallbacks...) {
+			dir := filepath.Dir(candidate.Dist)
+			if dirs[dir] {
+				continue
+			}
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return nil, utils.Errorf("创建输出目录失败: %w", err)
+			}
+			dirs[dir] = true
+		}
+	}
+
+	// 跳过清单中已完成的页面，中断后再次执行时从上次的进度继续
+	results, pending := make([]utils.BatchDownloadResult, len(task)), make([]int, len(task))
+	for i := range pending {
+		pending[i] = i
+	}
+	if !opts.Force {
+		results, pending = completedDownloads(ctx, opts.Output, task)
+	}
+	if len(pending) > 0 {
+		run := make([]utils.DownloadTask, len(pending))
+		for i, idx := range pending {
+			run[i] = task[idx]
+		}
+		downloader := &utils.Downloader{Client: opts.Client, Proxy: opts.Proxy, MaxRetries: 6}
+		for i, res := range downloader.BatchDownload(ctx, run, opts.Concurrency, opts.Events) {
+			results[pending[i]] = res
+		}
+	}
+
+	// 记录使用了备选地址的页面
+	for i, res := range results {
+		if res.Err == nil && res.Url != task[i].Url {
+			utils.LoggerFrom(ctx).Info("页面使用了备选格式",
+				utils.Str("original", task[i].Url),
+				utils.Str("url", res.Url),
+				utils.Str("dist", res.Dist))
 		}
 	}
+	recordManifest(ctx, utils.StageDownload, downloadedFiles(results))
+	return results, nil
+}
 
-	_ = utils.BatchDownload(task, proxy, concurrency, 6)
+// usePluginUrls 判断是否由插件提供下载地址
+func usePluginUrls(plugin *utils.Plugin) bool {
+	return plugin != nil && plugin.Supports(utils.PluginCapUrls)
 }
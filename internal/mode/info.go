@@ -0,0 +1,206 @@
+package mode
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"pickit/internal/utils"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pdfFormats 合成 PDF 支持的扩展名及其对应的图片格式，按扩展名识别
+var pdfFormats = map[string]string{
+	".jpg":  "jpeg",
+	".jpeg": "jpeg",
+	".png":  "png",
+	".gif":  "gif",
+}
+
+// PageInfo 目录中的一个文件
+type PageInfo struct {
+	Path     string
+	Format   string // 图片格式，如 jpeg、png、webp，不是图片时为空
+	Width    int
+	Height   int
+	Size     int64
+	Segments int   // 按车牌号计算的切割刀数，没有传入车牌号时为 -1
+	Err      error // 无法识别为图片的原因
+}
+
+// ChapterInfo 按 GetDirInfo 识别的一个章节，单层目录时只有一个名称为空的章节
+type ChapterInfo struct {
+	Name  string
+	Pages []PageInfo
+	Size  int64
+}
+
+// AlbumInfo 本子目录的信息
+type AlbumInfo struct {
+	Dir      string
+	Aid      int // 计算切割刀数使用的车牌号，为 0 时不计算
+	Chapters []ChapterInfo
+	Pages    int
+	Size     int64
+	Skipped  []utils.SkippedFile // 读取时跳过的文件
+	Warnings []string            // 会影响合成 PDF 的问题
+}
+
+// InspectDir 按 GetDirInfo 的方式读取目录，返回每一页的格式、尺寸、大小和切割刀数以及跳过的文件，并检查会影响合成 PDF 的问题
+func InspectDir(dir string, aid int, filter *utils.FileFilter) (*AlbumInfo, error) {
+	dirInfo, skipped, err := utils.GetDirInfo(dir, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &AlbumInfo{Dir: dir, Aid: aid, Skipped: skipped, Warnings: make([]string, 0)}
+	for _, d := range dirInfo {
+		chapter := ChapterInfo{Name: d.Name}
+		for _, file := range d.Files {
+			page := inspectPage(file, aid)
+			chapter.Pages = append(chapter.Pages, page)
+			chapter.Size += page.Size
+		}
+		info.Chapters = append(info.Chapters, chapter)
+		info.Pages += len(chapter.Pages)
+		info.Size += chapter.Size
+	}
+
+	if len(dirInfo) > 1 {
+		names := make([]string, len(dirInfo))
+		for i, d := range dirInfo {
+			names[i] = d.Name
+		}
+		if w := orderWarning(dir, names); w != "" {
+			info.Warnings = append(info.Warnings, w)
+		}
+	}
+	for _, chapter := range info.Chapters {
+		info.Warnings = append(info.Warnings, chapterWarnings(filepath.Join(dir, chapter.Name), chapter)...)
+	}
+	return info, nil
+}
+
+// inspectPage 读取文件的大小和图片信息，并按文件名计算切割刀数
+func inspectPage(file string, aid int) PageInfo {
+	page := PageInfo{Path: file, Segments: -1}
+	if aid > 0 {
+		page.Segments = utils.GetNum(220980, aid, trimExt(file))
+	}
+	if stat, err := os.Stat(file); err == nil {
+		page.Size = stat.Size()
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		page.Err = utils.Errorf("打开文件失败: %w", err)
+		return page
+	}
+	defer f.Close()
+	cfg, format, err := image.DecodeConfig(f)
+	if err != nil {
+		page.Err = fmt.Errorf("%w: %w", utils.ErrCorruptImage, err)
+		return page
+	}
+	page.Format, page.Width, page.Height = format, cfg.Width, cfg.Height
+	return page
+}
+
+// chapterWarnings 检查章节中会影响合成 PDF 的问题
+func chapterWarnings(dir string, chapter ChapterInfo) []string {
+	warnings := make([]string, 0)
+	if len(chapter.Pages) == 0 {
+		return append(warnings, fmt.Sprintf(utils.T("%s 中没有文件"), dir))
+	}
+
+	names := make([]string, 0, len(chapter.Pages))
+	widths := make(map[int]int)
+	common, maxCount := 0, 0 // 与合成 PDF 时相同，缩放到最先达到最多次数的宽度
+	for _, page := range chapter.Pages {
+		name := filepath.Base(page.Path)
+		names = append(names, name)
+		if page.Err != nil {
+			warnings = append(warnings, fmt.Sprintf(utils.T("%s 不是有效的图片，合成 PDF 时会被跳过"), page.Path))
+			continue
+		}
+		widths[page.Width]++
+		if widths[page.Width] > maxCount {
+			common, maxCount = page.Width, widths[page.Width]
+		}
+
+		switch expected := pdfFormats[strings.ToLower(filepath.Ext(name))]; {
+		case !pdfFormat(page.Format):
+			warnings = append(warnings, fmt.Sprintf(utils.T("%s 的格式为 %s，合成 PDF 不支持，需要先用 restore 转换为 JPEG"), page.Path, page.Format))
+		case expected != page.Format:
+			warnings = append(warnings, fmt.Sprintf(utils.T("%s 的扩展名与实际格式 %s 不一致，合成 PDF 时会被跳过"), page.Path, page.Format))
+		}
+	}
+
+	if len(widths) > 1 {
+		list := make([]int, 0, len(widths))
+		for w := range widths {
+			list = append(list, w)
+		}
+		sort.Ints(list)
+		warnings = append(warnings, fmt.Sprintf(utils.T("%s 中的图片宽度不一致 %v，合成 PDF 时会缩放到 %d"), dir, list, common))
+	}
+
+	if w := orderWarning(dir, names); w != "" {
+		warnings = append(warnings, w)
+	}
+	return warnings
+}
+
+// pdfFormat 判断合成 PDF 是否支持该图片格式
+func pdfFormat(format string) bool {
+	for _, f := range pdfFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// orderWarning 名称不全是数字时按字符串排序，与自然顺序不同时（如 p10 排在 p2 之前）返回警告
+func orderWarning(dir string, names []string) string {
+	for i := 1; i < len(names); i++ {
+		a, b := names[i-1], names[i]
+		if naturalLess(b, a) {
+			return fmt.Sprintf(utils.T("%s 中的名称不是纯数字，按字符串排序后 %s 排在 %s 之前，页面顺序可能不对"), dir, a, b)
+		}
+	}
+	return ""
+}
+
+// naturalLess 按自然顺序比较名称，连续的数字按数值比较
+func naturalLess(a, b string) bool {
+	a, b = trimExt(a), trimExt(b)
+	for a != "" && b != "" {
+		da, db := leadingDigits(a), leadingDigits(b)
+		if da != "" && db != "" {
+			na, _ := strconv.Atoi(da)
+			nb, _ := strconv.Atoi(db)
+			if na != nb {
+				return na < nb
+			}
+			a, b = a[len(da):], b[len(db):]
+			continue
+		}
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+// leadingDigits 返回开头连续的数字
+func leadingDigits(s string) string {
+	i := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if i < 0 {
+		return s
+	}
+	return s[:i]
+}
@@ -0,0 +1,264 @@
+package mode
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"pickit/internal/utils"
+	"strconv"
+	"strings"
+)
+
+// jobPageRef 指向某个任务中的某一页
+type jobPageRef struct {
+	job  *utils.Job
+	page int
+}
+
+// RunJobs 执行任务，已完成的页面会被跳过，因此中断后再次执行会从上次的进度继续。
+// 每一页的状态变化都会立即写入任务文件。所有任务的下载共用一个下载协程池和 HTTP 客户端 client（为 nil 时使用默认设置），
+// 单个任务失败不影响其他任务。
+func RunJobs(ctx context.Context, store *utils.JobStore, jobs []*utils.Job, provider utils.MetadataProvider, concurrency int, client *http.Client) {
+	logger := utils.LoggerFrom(ctx)
+	active := make([]*utils.Job, 0, len(jobs))
+	for _, job := range jobs {
+		if job.Status == utils.JobCancelled || job.Status == utils.JobDone {
+			logger.Info("跳过任务", utils.Str("job", job.Id), utils.Str("status", job.Status))
+			continue
+		}
+
+		job.SetError(nil)
+		if len(job.Pages) == 0 {
+			if err := planJob(ctx, job, provider); err != nil {
+				job.Status = utils.JobFailed
+				job.SetError(err)
+				logger.Error("任务解析失败", utils.Str("job", job.Id), utils.Str("aid", job.Input), utils.Err(err))
+				saveJob(ctx, store, job)
+				continue
+			}
+		}
+		job.Status = utils.JobRunning
+		saveJob(ctx, store, job)
+		active = append(active, job)
+	}
+
+	downloadJobPages(ctx, store, active, concurrency, client)
+	for _, job := range active {
+		if job.Export != utils.ExportRaw {
+			restoreJobPages(ctx, store, job, concurrency)
+		}
+		if job.Export == utils.ExportPdf {
+			exportJobPdf(ctx, store, job)
+		}
+
+		switch {
+		case job.Error != "":
+			job.Status = utils.JobFailed
+		case job.Progress() == len(job.Pages):
+			job.Status = utils.JobDone
+		default:
+			job.Status = utils.JobFailed
+		}
+		saveJob(ctx, store, job)
+
+		logger.Info("任务处理完成",
+			utils.Str("job", job.Id),
+			utils.Str("aid", job.Input),
+			utils.Str("status", job.Status),
+			utils.Int("pages", len(job.Pages)),
+			utils.Int("done", job.Progress()),
+			utils.Str("output", job.Output))
+	}
+}
+
+// planJob 确定任务需要下载的页面并写入任务
+func planJob(ctx context.Context, job *utils.Job, provider utils.MetadataProvider) error {
+	album := utils.BatchAlbum{
+		Aid:      job.Input,
+		Cdn:      job.Cdn,
+		Count:    job.Count,
+		Output:   job.Output,
+		Export:   job.Export,
+		Chapters: job.Chapters,
+	}
+	aid, units, err := resolveBatchUnits(ctx, album, provider)
+	if err != nil {
+		return err
+	}
+	job.Aid = aid
+
+	opts := DownloadOptions{
+		Cdn:          job.Cdn,
+		FallbackExts: utils.DefaultFallbackExts,
+	}
+	for _, unit := range units {
+		tasks, err := buildDownloadTasks(ctx, opts, unit.chapter, unit.aid, unit.count, unit.rawDir)
+		if err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			page := utils.JobPage{
+				Chapter: unit.chapter,
+				Aid:     unit.aid,
+				Url:     task.Url,
+				Raw:     task.Dist,
+				State:   utils.PagePending,
+			}
+			if unit.imagesDir != "" {
+				page.Image = path.Join(unit.imagesDir, trimExt(task.Dist)+".jpeg")
+			}
+			job.Pages = append(job.Pages, page)
+		}
+	}
+	if len(job.Pages) == 0 {
+		return utils.Errorf("没有需要下载的页面")
+	}
+	return nil
+}
+
+// downloadJobPages 下载所有任务中尚未下载的页面
+func downloadJobPages(ctx context.Context, store *utils.JobStore, jobs []*utils.Job, concurrency int, client *http.Client) {
+	refs := make([]jobPageRef, 0)
+	tasks := make([]utils.DownloadTask, 0)
+	for _, job := range jobs {
+		for i, page := range job.Pages {
+			if page.State != utils.PagePending {
+				continue
+			}
+			if err := os.MkdirAll(filepath.Dir(page.Raw), 0755); err != nil {
+				job.SetError(utils.Errorf("创建输出目录失败: %w", err))
+				break
+			}
+			refs = append(refs, jobPageRef{job: job, page: i})
+			tasks = append(tasks, jobDownloadTask(page))
+		}
+	}
+	if len(tasks) == 0 {
+		return
+	}
+
+	downloader := &utils.Downloader{Client: client, MaxRetries: 6}
+	results := downloader.BatchDownload(ctx, tasks, concurrency, utils.EventFunc(func(e utils.Event) {
+		if e.Type != utils.EventItemSucceeded && e.Type != utils.EventItemFailed {
+			return
+		}
+		ref := refs[e.Index]
+		err := store.Update(ref.job, func() {
+			page := &ref.job.Pages[ref.page]
+			if e.Err != nil {
+				page.SetError(e.Err)
+				return
+			}
+			page.Raw, page.State = e.Output, utils.PageDownloaded
+			page.SetError(nil)
+			if page.Image != "" {
+				page.Image = path.Join(path.Dir(page.Image), trimExt(e.Output)+".jpeg")
+			}
+		})
+		if err != nil {
+			utils.LoggerFrom(ctx).Error("保存任务进度失败", utils.Str("job", ref.job.Id), utils.Err(err))
+		}
+	}))
+
+	// 页面记录到所在目录的清单中
+	recordManifest(ctx, utils.StageDownload, downloadedFiles(results))
+}
+
+// jobDownloadTask 构建页面的下载任务，404 时依次尝试默认的备选扩展名
+func jobDownloadTask(page utils.JobPage) utils.DownloadTask {
+	task := utils.DownloadTask{Url: page.Url, Dist: page.Raw}
+	dir := filepath.Dir(page.Raw)
+	for _, ext := range utils.DefaultFallbackExts {
+		fallbackUrl := utils.ReplaceUrlExt(page.Url, ext)
+		if fallbackUrl == page.Url {
+			continue
+		}
+		task.Fallbacks = append(task.Fallbacks, utils.DownloadTask{
+			Url:  fallbackUrl,
+			Dist: path.Join(dir, trimExt(page.Raw)+"."+ext),
+		})
+	}
+	return task
+}
+
+// restoreJobPages 还原任务中已下载但尚未还原的页面，按章节分组处理
+func restoreJobPages(ctx context.Context, store *utils.JobStore, job *utils.Job, concurrency int) {
+	groups := make(map[int][]int)
+	order := make([]int, 0)
+	for i, page := range job.Pages {
+		if page.State != utils.PageDownloaded {
+			continue
+		}
+		if _, ok := groups[page.Aid]; !ok {
+			order = append(order, page.Aid)
+		}
+		groups[page.Aid] = append(groups[page.Aid], i)
+	}
+
+	for _, aid := range order {
+		indexes := groups[aid]
+		tasks := make([]utils.DecodeAndSaveTask, 0, len(indexes))
+		for _, i := range indexes {
+			page := job.Pages[i]
+			if err := os.MkdirAll(filepath.Dir(page.Image), 0755); err != nil {
+				job.SetError(utils.Errorf("创建输出目录失败: %w", err))
+				return
+			}
+			tasks = append(tasks, utils.DecodeAndSaveTask{ImgSrcPath: page.Raw, DecodedSavePath: page.Image})
+		}
+
+		results := utils.BatchDecodeAndSave(ctx, 220980, aid, tasks, concurrency, utils.EventFunc(func(e utils.Event) {
+			if e.Type != utils.EventItemSucceeded && e.Type != utils.EventItemFailed {
+				return
+			}
+			saveErr := store.Update(job, func() {
+				page := &job.Pages[indexes[e.Index]]
+				if e.Err != nil {
+					page.SetError(e.Err)
+					return
+				}
+				page.State = utils.PageRestored
+				page.SetError(nil)
+			})
+			if saveErr != nil {
+				utils.LoggerFrom(ctx).Error("保存任务进度失败", utils.Str("job", job.Id), utils.Err(saveErr))
+			}
+		}))
+		recordManifest(ctx, utils.StageRestore, restoredFiles(results))
+	}
+}
+
+// exportJobPdf 所有页面都还原后合成 PDF
+func exportJobPdf(ctx context.Context, store *utils.JobStore, job *utils.Job) {
+	for _, page := range job.Pages {
+		if page.State != utils.PageRestored && page.State != utils.PageExported {
+			utils.LoggerFrom(ctx).Warn("任务中有未还原的页面，跳过合成 PDF", utils.Str("job", job.Id))
+			return
+		}
+	}
+
+	pdfPath := path.Join(job.Output, strconv.Itoa(job.Aid)+".pdf")
+	if _, err := CreatePDF(ctx, path.Join(job.Output, "images"), pdfPath, job.Password, nil, nil, nil); err != nil {
+		job.SetError(utils.Errorf("合成 PDF 失败: %w", err))
+		return
+	}
+	for i := range job.Pages {
+		job.Pages[i].State = utils.PageExported
+	}
+	saveJob(ctx, store, job)
+}
+
+// saveJob 保存任务，失败时只记录日志，下次执行时会重新处理未记录的页面
+func saveJob(ctx context.Context, store *utils.JobStore, job *utils.Job) {
+	if err := store.Save(job); err != nil {
+		utils.LoggerFrom(ctx).Error("保存任务失败", utils.Str("job", job.Id), utils.Err(err))
+	}
+}
+
+// trimExt 返回不带扩展名的文件名
+func trimExt(file string) string {
+	name := filepath.Base(file)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
@@ -0,0 +1,191 @@
+package mode
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"pickit/internal/utils"
+	"sync"
+)
+
+// manifestLocks 每个清单目录一把锁，批量任务共用输出目录时读取、合并、保存清单不会互相覆盖
+var manifestLocks sync.Map
+
+// manifestFile 需要写入清单的文件
+type manifestFile struct {
+	source   string // 下载地址、原图路径或 PDF 的图片目录
+	path     string // 输出文件
+	segments *int   // 还原时的切割刀数
+}
+
+// recordManifest 把处理成功的文件写入文件所在目录的清单，verify 任一层目录都能找到。
+// 清单只用于校验和续传，失败时只记录日志，不影响处理结果
+func recordManifest(ctx context.Context, stage string, files []manifestFile) {
+	byDir := make(map[string][]manifestFile)
+	order := make([]string, 0)
+	for _, f := range files {
+		dir := filepath.Dir(f.path)
+		if _, ok := byDir[dir]; !ok {
+			order = append(order, dir)
+		}
+		byDir[dir] = append(byDir[dir], f)
+	}
+	for _, dir := range order {
+		recordManifestDir(ctx, dir, stage, byDir[dir])
+	}
+}
+
+// recordManifestDir 把文件写入 dir 中的清单，同一目录的读取、合并和保存串行执行
+func recordManifestDir(ctx context.Context, dir, stage string, files []manifestFile) {
+	mu := manifestLock(dir)
+	mu.Lock()
+	defer mu.Unlock()
+
+	logger := utils.LoggerFrom(ctx)
+	manifest, err := utils.LoadManifest(dir)
+	if err != nil {
+		logger.Warn("更新清单失败", utils.Str("dir", dir), utils.Err(err))
+		return
+	}
+	for _, f := range files {
+		entry, err := manifest.NewEntry(stage, f.source, f.path, f.segments)
+		if err != nil {
+			logger.Warn("记录清单失败", utils.Str("path", f.path), utils.Err(err))
+			continue
+		}
+		manifest.Add(entry)
+	}
+	if err := manifest.Save(); err != nil {
+		logger.Warn("更新清单失败", utils.Str("dir", dir), utils.Err(err))
+		return
+	}
+	logger.Debug("清单已更新", utils.Str("dir", dir), utils.Int("files", len(files)))
+}
+
+// manifestLock 返回 dir 中清单的锁
+func manifestLock(dir string) *sync.Mutex {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	mu, _ := manifestLocks.LoadOrStore(dir, &sync.Mutex{})
+	return mu.(*sync.Mutex)
+}
+
+// downloadedFiles 下载成功的页面，不包括跳过的页面
+func downloadedFiles(results []utils.BatchDownloadResult) []manifestFile {
+	files := make([]manifestFile, 0, len(results))
+	for _, res := range results {
+		if res.Err == nil && !res.Skipped {
+			files = append(files, manifestFile{source: res.Url, path: res.Dist})
+		}
+	}
+	return files
+}
+
+// restoredFiles 还原成功的图片，不包括跳过的图片
+func restoredFiles(results []utils.DecodeAndSaveResult) []manifestFile {
+	files := make([]manifestFile, 0, len(results))
+	for _, res := range results {
+		if res.Err == nil && !res.Skipped {
+			segments := res.Segments
+			files = append(files, manifestFile{source: res.ImgSrcPath, path: res.DecodedSavePath, segments: &segments})
+		}
+	}
+	return files
+}
+
+// completedDownloads 按页面所在目录的清单找出已经下载完成的页面（原地址或任一备选地址，内容与清单一致），
+// 返回与 task 等长的结果，已完成的页面 Skipped 为 true，以及需要下载的任务在 task 中的下标。dir 为输出目录，只用于日志
+func completedDownloads(ctx context.Context, dir string, task []utils.DownloadTask) ([]utils.BatchDownloadResult, []int) {
+	results := make([]utils.BatchDownloadResult, len(task))
+	pending := make([]int, 0, len(task))
+	manifests := resumeManifests{ctx: ctx}
+	for i, t := range task {
+		done := false
+		for _, candidate := range append([]utils.DownloadTask{t}, t.Fallbacks...) {
+			manifest := manifests.get(candidate.Dist)
+			if manifest == nil {
+				continue
+			}
+			if _, ok := manifest.Completed(utils.StageDownload, candidate.Url, candidate.Dist); ok {
+				results[i] = utils.BatchDownloadResult{Url: candidate.Url, Dist: candidate.Dist, Skipped: true}
+				done = true
+				break
+			}
+		}
+		if !done {
+			pending = append(pending, i)
+		}
+	}
+	logResume(ctx, dir, len(task), len(pending))
+	return results, pending
+}
+
+// completedRestores 按 dir 中的清单找出已经还原完成的图片：来源相同、内容与清单一致，并且原图没有在还原之后被修改。
+// 返回值同 completedDownloads，跳过的结果中带有清单记录的切割刀数
+func completedRestores(ctx context.Context, dir string, task []utils.DecodeAndSaveTask) ([]utils.DecodeAndSaveResult, []int) {
+	results := make([]utils.DecodeAndSaveResult, len(task))
+	pending := make([]int, 0, len(task))
+	manifests := resumeManifests{ctx: ctx}
+	for i, t := range task {
+		if manifest := manifests.get(t.DecodedSavePath); manifest != nil {
+			entry, ok := manifest.Completed(utils.StageRestore, t.ImgSrcPath, t.DecodedSavePath)
+			if ok && !newerThan(t.ImgSrcPath, t.DecodedSavePath) {
+				results[i] = utils.DecodeAndSaveResult{ImgSrcPath: t.ImgSrcPath, DecodedSavePath: t.DecodedSavePath, Skipped: true}
+				if entry.Segments != nil {
+					results[i].Segments = *entry.Segments
+				}
+				continue
+			}
+		}
+		pending = append(pending, i)
+	}
+	logResume(ctx, dir, len(task), len(pending))
+	return results, pending
+}
+
+// resumeManifests 按目录缓存用于跳过已完成页面的清单
+type resumeManifests struct {
+	ctx  context.Context
+	dirs map[string]*utils.Manifest
+}
+
+// get 返回 file 所在目录的清单，读取失败时返回 nil，该目录的页面都会重新处理
+func (r *resumeManifests) get(file string) *utils.Manifest {
+	dir := filepath.Dir(file)
+	if manifest, ok := r.dirs[dir]; ok {
+		return manifest
+	}
+	if r.dirs == nil {
+		r.dirs = make(map[string]*utils.Manifest)
+	}
+	manifest, err := utils.LoadManifest(dir)
+	if err != nil {
+		utils.LoggerFrom(r.ctx).Warn("读取清单失败，重新处理该目录的页面", utils.Str("dir", dir), utils.Err(err))
+	}
+	r.dirs[dir] = manifest
+	return manifest
+}
+
+// logResume 记录跳过的页数
+func logResume(ctx context.Context, dir string, total, pending int) {
+	if pending < total {
+		utils.LoggerFrom(ctx).Info("跳过清单中已完成的页面",
+			utils.Str("dir", dir),
+			utils.Int("skipped", total-pending),
+			utils.Int("pending", pending))
+	}
+}
+
+// newerThan 判断 a 的修改时间是否晚于 b，无法读取时视为较新
+func newerThan(a, b string) bool {
+	sa, err := os.Stat(a)
+	if err != nil {
+		return true
+	}
+	sb, err := os.Stat(b)
+	if err != nil {
+		return true
+	}
+	return sa.ModTime().After(sb.ModTime())
+}
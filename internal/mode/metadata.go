@@ -0,0 +1,51 @@
+package mode
+
+import (
+	"context"
+	"pickit/internal/utils"
+)
+
+// ResolveChapters 通过本子信息确定需要下载的章节。
+// 本子包含多个章节时 multi 为 true，此时每个章节应下载到单独的子目录中。
+func ResolveChapters(ctx context.Context, provider utils.MetadataProvider, ref utils.AlbumRef, selection utils.IntRanges) (chapters []utils.ChapterMeta, multi bool, err error) {
+	_, chapters, multi, err = ResolveAlbum(ctx, provider, ref, selection)
+	return chapters, multi, err
+}
+
+// ResolveAlbum 与 ResolveChapters 相同，同时返回本子信息。传入章节链接时返回的本子信息只包含该章节的编号和标题
+func ResolveAlbum(ctx context.Context, provider utils.MetadataProvider, ref utils.AlbumRef, selection utils.IntRanges) (album *utils.AlbumMeta, chapters []utils.ChapterMeta, multi bool, err error) {
+	if provider == nil {
+		return nil, nil, false, utils.Errorf("未传入图片数量，且没有配置本子信息接口")
+	}
+
+	if ref.Kind == utils.AlbumRefChapter {
+		if selection != nil {
+			return nil, nil, false, utils.Errorf("章节链接不支持选择章节，请传入本子编号")
+		}
+		chapter, err := provider.Chapter(ctx, ref.Id)
+		if err != nil {
+			return nil, nil, false, utils.Errorf("获取章节信息失败: %w", err)
+		}
+		album = &utils.AlbumMeta{Id: chapter.Id, Title: chapter.Title, Chapters: []utils.ChapterMeta{*chapter}}
+		return album, album.Chapters, false, nil
+	}
+
+	album, err = provider.Album(ctx, ref.Id)
+	if err != nil {
+		return nil, nil, false, utils.Errorf("获取本子信息失败: %w", err)
+	}
+	if len(album.Chapters) <= 1 && selection == nil {
+		return album, album.Chapters, false, nil
+	}
+
+	chapters = make([]utils.ChapterMeta, 0, len(album.Chapters))
+	for _, chapter := range album.Chapters {
+		if selection == nil || selection.Contains(chapter.Sort) {
+			chapters = append(chapters, chapter)
+		}
+	}
+	if len(chapters) == 0 {
+		return nil, nil, false, utils.Errorf("本子 %d 共 %d 个章节，没有符合条件的章节", album.Id, len(album.Chapters))
+	}
+	return album, chapters, true, nil
+}
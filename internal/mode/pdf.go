@@ -1,10 +1,23 @@
 package mode
 
-import "pickit/internal/utils"
+import (
+	"context"
+	"pickit/internal/utils"
+)
 
-func CreatePDF(input, output, password string) {
-	err := utils.ConvertImagesToPDF(input, output, password)
+// CreatePDF 将 input 中的图片合成 PDF，多个章节时按章节添加书签，处理过程中向 events（可以为 nil）发送事件。
+// filter 为 nil 时只跳过隐藏文件、临时文件和不是图片的文件，返回读取目录时跳过的文件（同时记录在日志中）
+func CreatePDF(ctx context.Context, input, output, password string, selection *utils.PageSelection, filter *utils.FileFilter, events utils.EventHandler) ([]utils.SkippedFile, error) {
+	files, skipped, err := utils.GetDirInfo(input, filter)
 	if err != nil {
-		utils.LogFatal("Failed to convert images to pdf")
+		return nil, err
 	}
+	utils.LogSkipped(ctx, skipped)
+	files = utils.FilterDirInfo(files, selection)
+
+	if err := utils.ConvertDirInfoToPDF(ctx, files, output, password, events); err != nil {
+		return skipped, err
+	}
+	recordManifest(ctx, utils.StagePdf, []manifestFile{{source: input, path: output}})
+	return skipped, nil
 }
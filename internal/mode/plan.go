@@ -0,0 +1,317 @@
+package mode
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"pickit/internal/utils"
+	"strconv"
+	"time"
+)
+
+// PlanItem 演练模式下计划执行的一项操作
+type PlanItem struct {
+	Stage   string // download、restore、pdf
+	Input   string // 下载地址或输入文件
+	Output  string // 输出文件
+	Exists  bool   // 输出文件已存在，实际执行时会被覆盖
+	Skipped bool   // 清单中已完成，实际执行时跳过
+	Size    int64  // 下载大小，未知或不是下载时为 -1
+	Err     error  // 无法执行的原因，如下载地址不可用
+}
+
+// Plan 演练模式的执行计划，生成计划时只读取本地文件和发送 HEAD 请求，不写入任何文件
+type Plan struct {
+	Items       []PlanItem
+	Concurrency int                 // 估算耗时使用的并发数
+	Downloads   int                 // 下载的页数
+	TotalSize   int64               // 已知大小的下载总量
+	UnknownSize int                 // 无法获取大小的下载数
+	Estimate    time.Duration       // 预计下载耗时，无法估算时为 0
+	Skipped     []utils.SkippedFile // 读取图片目录时跳过的文件，只有还原和合成 PDF 有
+}
+
+// Overwrites 返回会覆盖已有文件的项数
+func (p *Plan) Overwrites() int {
+	count := 0
+	for _, item := range p.Items {
+		if item.Exists {
+			count++
+		}
+	}
+	return count
+}
+
+// Completed 返回清单中已完成、实际执行时跳过的项数
+func (p *Plan) Completed() int {
+	count := 0
+	for _, item := range p.Items {
+		if item.Skipped {
+			count++
+		}
+	}
+	return count
+}
+
+// Failed 返回无法执行的项数
+func (p *Plan) Failed() int {
+	count := 0
+	for _, item := range p.Items {
+		if item.Err != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// PlanDownloadRef 生成下载本子或章节的计划，参数同 DownloadRef
+func PlanDownloadRef(ctx context.Context, opts DownloadOptions, provider utils.MetadataProvider, ref utils.AlbumRef, chapterSelection utils.IntRanges) (*Plan, error) {
+	task, err := refDownloadTasks(ctx, opts, provider, ref, chapterSelection)
+	if err != nil {
+		return nil, err
+	}
+	plan := &Plan{Concurrency: opts.Concurrency}
+	planDownloadTasks(ctx, plan, opts, task)
+	return plan, nil
+}
+
+// PlanRestore 生成还原图片的计划，参数同 RestoreImages
+func PlanRestore(input, output string, aid int, selection *utils.PageSelection, filter *utils.FileFilter, pageName *utils.NameTemplate) (*Plan, error) {
+	task, _, skipped, err := restoreTasks(input, output, aid, selection, filter, pageName)
+	if err != nil {
+		return nil, err
+	}
+	plan := &Plan{Skipped: skipped}
+	for _, t := range task {
+		plan.add(utils.StageRestore, t.ImgSrcPath, t.DecodedSavePath)
+	}
+	return plan, nil
+}
+
+// PlanPDF 生成合成 PDF 的计划，每张图片为一项，最后一项为生成的 PDF
+func PlanPDF(input, output string, selection *utils.PageSelection, filter *utils.FileFilter) (*Plan, error) {
+	files, skipped, err := utils.GetDirInfo(input, filter)
+	if err != nil {
+		return nil, err
+	}
+	files = utils.FilterDirInfo(files, selection)
+
+	plan := &Plan{Skipped: skipped}
+	for _, dir := range files {
+		for _, file := range dir.Files {
+			plan.Items = append(plan.Items, PlanItem{Stage: utils.StagePdf, Input: file, Size: -1})
+		}
+	}
+	if len(plan.Items) == 0 {
+		return nil, utils.Errorf("没有需要合成的图片")
+	}
+	plan.add(utils.StagePdf, input, output)
+	return plan, nil
+}
+
+// PlanBatch 生成批量任务的计划。有可以继续执行的任务时只计划尚未完成的页面，不创建或修改任务
+func PlanBatch(ctx context.Context, file *utils.BatchFile, store *utils.JobStore, provider utils.MetadataProvider, concurrency int, client *http.Client) *Plan {
+	plan := &Plan{Concurrency: concurrency}
+	downloads := make([]utils.DownloadTask, 0)
+	later := make([]PlanItem, 0)
+	restoreFrom := make(map[int]int) // 还原项下标 -> 提供原图的下载项下标
+	for _, album := range file.Albums {
+		job, err := findBatchJob(store, album)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			utils.LoggerFrom(ctx).Warn("读取任务失败", utils.Str("aid", album.Aid), utils.Err(err))
+		}
+		if job == nil {
+			job = newBatchJob(album)
+		}
+		if len(job.Pages) == 0 {
+			if err := planJob(ctx, job, provider); err != nil {
+				plan.Items = append(plan.Items, PlanItem{Stage: utils.StageDownload, Input: album.Aid, Output: album.Output, Size: -1, Err: err})
+				continue
+			}
+		}
+
+		for _, page := range job.Pages {
+			if page.Image != "" && (page.State == utils.PagePending || page.State == utils.PageDownloaded) {
+				if page.State == utils.PagePending {
+					restoreFrom[len(later)] = len(downloads)
+				}
+				later = append(later, planItem(utils.StageRestore, page.Raw, page.Image))
+			}
+			if page.State == utils.PagePending {
+				downloads = append(downloads, jobDownloadTask(page))
+			}
+		}
+		if job.Export == utils.ExportPdf {
+			later = append(later, planItem(utils.StagePdf, path.Join(job.Output, "images"),
+				path.Join(job.Output, strconv.Itoa(job.Aid)+".pdf")))
+		}
+	}
+
+	downloader := &utils.Downloader{Client: client}
+	offset := len(plan.Items)
+	planDownloads(ctx, plan, downloader, downloads)
+
+	// 原地址 404 时会下载备选格式，还原的输入改为实际下载的文件
+	for i, d := range restoreFrom {
+		later[i].Input = plan.Items[offset+d].Output
+	}
+	plan.Items = append(plan.Items, later...)
+	return plan
+}
+
+// PlanSync 生成同步订阅的计划：新章节的下载、还原，以及有新章节时重新合成的 PDF，参数同 SyncSubscription。
+// 获取本子信息失败时计划中只有一项带错误的下载项，不修改订阅
+func PlanSync(ctx context.Context, sub *utils.Subscription, provider utils.MetadataProvider, client *http.Client) *Plan {
+	opts := syncDownloadOptions(sub, client)
+	plan := &Plan{Concurrency: opts.Concurrency}
+	_, newChapters, err := subscriptionChapters(ctx, sub, provider)
+	if err != nil {
+		plan.Items = append(plan.Items, PlanItem{Stage: utils.StageDownload, Input: strconv.Itoa(sub.Aid), Output: sub.Output, Size: -1, Err: err})
+		return plan
+	}
+
+	imagesDir := path.Join(sub.Output, "images")
+	downloads := make([]utils.DownloadTask, 0)
+	later := make([]PlanItem, 0)
+	for _, chapter := range newChapters {
+		task, err := chapterDownloadTasks(ctx, opts, []utils.ChapterMeta{chapter})
+		if err != nil {
+			later = append(later, PlanItem{Stage: utils.StageDownload, Input: strconv.Itoa(chapter.Id), Size: -1, Err: err})
+			continue
+		}
+		dir := path.Join(imagesDir, strconv.Itoa(chapter.Sort))
+		for _, t := range task {
+			later = append(later, planItem(utils.StageRestore, t.Dist, path.Join(dir, trimExt(t.Dist)+".jpeg")))
+			downloads = append(downloads, t)
+		}
+	}
+	if len(newChapters) > 0 && sub.Pdf {
+		later = append(later, planItem(utils.StagePdf, imagesDir, path.Join(sub.Output, strconv.Itoa(sub.Aid)+".pdf")))
+	}
+
+	// 原地址 404 时会下载备选格式，已完成的页面使用清单中的文件，还原的输入改为实际的原图
+	dists := planDownloadTasks(ctx, plan, opts, downloads)
+	d := 0
+	for i := range later {
+		if later[i].Stage == utils.StageRestore {
+			later[i].Input = dists[d]
+			d++
+		}
+	}
+	plan.Items = append(plan.Items, later...)
+	return plan
+}
+
+// planDownloadTasks 与 runDownloadTasks 一样跳过清单中已完成的页面（opts.Force 时不跳过），
+// 探测其余页面的大小，按页面顺序把下载项加入计划，返回每个任务实际的保存路径
+func planDownloadTasks(ctx context.Context, plan *Plan, opts DownloadOptions, task []utils.DownloadTask) []string {
+	results, pending := make([]utils.BatchDownloadResult, len(task)), make([]int, len(task))
+	for i := range pending {
+		pending[i] = i
+	}
+	if !opts.Force {
+		results, pending = completedDownloads(ctx, opts.Output, task)
+	}
+	run := make([]utils.DownloadTask, len(pending))
+	for i, idx := range pending {
+		run[i] = task[idx]
+	}
+
+	downloads := &Plan{Concurrency: plan.Concurrency}
+	planDownloads(ctx, downloads, &utils.Downloader{Client: opts.Client, Proxy: opts.Proxy}, run)
+	items := make([]PlanItem, len(task))
+	for i, res := range results {
+		if res.Skipped {
+			items[i] = PlanItem{Stage: utils.StageDownload, Input: res.Url, Output: res.Dist, Size: -1, Skipped: true}
+		}
+	}
+	for i, idx := range pending {
+		items[idx] = downloads.Items[i]
+	}
+	downloads.Items = items
+	plan.Merge(downloads)
+
+	dists := make([]string, len(items))
+	for i, item := range items {
+		dists[i] = item.Output
+	}
+	return dists
+}
+
+// Merge 把 other 的各项追加到计划中，下载量和预计耗时累加，并发数取较大值
+func (p *Plan) Merge(other *Plan) {
+	p.Items = append(p.Items, other.Items...)
+	p.Downloads += other.Downloads
+	p.TotalSize += other.TotalSize
+	p.UnknownSize += other.UnknownSize
+	p.Estimate += other.Estimate
+	p.Concurrency = max(p.Concurrency, other.Concurrency)
+	p.Skipped = append(p.Skipped, other.Skipped...)
+}
+
+// planDownloads 探测下载任务的大小，并按单个连接的下载速度估算耗时
+func planDownloads(ctx context.Context, plan *Plan, downloader *utils.Downloader, task []utils.DownloadTask) {
+	if plan.Concurrency <= 0 {
+		plan.Concurrency = 1
+	}
+	probes := downloader.BatchProbe(ctx, task, plan.Concurrency)
+
+	var latency time.Duration
+	sample, ok := "", 0
+	for _, p := range probes {
+		item := planItem(utils.StageDownload, p.Url, p.Dist)
+		item.Size, item.Err = p.Size, p.Err
+		plan.Items = append(plan.Items, item)
+		plan.Downloads++
+		if p.Err != nil {
+			continue
+		}
+		ok++
+		latency += p.Duration
+		if p.Size >= 0 {
+			plan.TotalSize += p.Size
+		} else {
+			plan.UnknownSize++
+		}
+		if sample == "" {
+			sample = p.Url
+		}
+	}
+	// 所有页面的大小都未知时无法估算
+	if sample == "" || plan.TotalSize == 0 {
+		return
+	}
+
+	// 用第一页测量单个连接的速度，每页耗时按请求延迟加传输时间计算，再平均分配到各个并发连接
+	speed, err := downloader.MeasureThroughput(ctx, sample)
+	if err != nil || speed <= 0 {
+		utils.LoggerFrom(ctx).Warn("测量下载速度失败，无法估算耗时", utils.Str("url", sample), utils.Err(err))
+		return
+	}
+	transfer := time.Duration(float64(plan.TotalSize) / speed * float64(time.Second))
+	if plan.UnknownSize > 0 && ok > plan.UnknownSize {
+		// 大小未知的页面按已知页面的平均大小计算
+		transfer += transfer / time.Duration(ok-plan.UnknownSize) * time.Duration(plan.UnknownSize)
+	}
+	workers := min(plan.Concurrency, ok)
+	plan.Estimate = (latency + transfer) / time.Duration(workers)
+}
+
+// add 添加一项非下载的操作
+func (p *Plan) add(stage, input, output string) {
+	p.Items = append(p.Items, planItem(stage, input, output))
+}
+
+// planItem 创建计划项并检查输出文件是否已存在
+func planItem(stage, input, output string) PlanItem {
+	item := PlanItem{Stage: stage, Input: input, Output: output, Size: -1}
+	if output != "" {
+		if _, err := os.Stat(output); err == nil {
+			item.Exists = true
+		}
+	}
+	return item
+}
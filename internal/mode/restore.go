@@ -1,36 +1,102 @@
 package mode
 
 import (
-	"path"
+	"context"
+	"os"
 	"path/filepath"
 	"pickit/internal/utils"
 	"strings"
 )
 
-func RestoreImages(input, output string, aid, concurrency int) {
-	dirInfo, err := utils.GetDirInfo(input)
+// RestoreImages 还原 input 中的图片并保存到 output，pageName 为输出文件名模板（为 nil 时使用 {name}.jpeg），
+// 其中 {page} 为图片在目录中的序号，{ext} 固定为 jpeg。filter 为 nil 时只跳过隐藏文件、临时文件和不是图片的文件。
+// 返回每张图片的处理结果和读取目录时跳过的文件（同时记录在日志中），处理过程中向 events（可以为 nil）发送事件
+func RestoreImages(ctx context.Context, input, output string, aid, concurrency int, plugin *utils.Plugin, selection *utils.PageSelection, filter *utils.FileFilter, pageName *utils.NameTemplate, events utils.EventHandler, force bool) ([]utils.DecodeAndSaveResult, []utils.SkippedFile, error) {
+	task, names, skipped, err := restoreTasks(input, output, aid, selection, filter, pageName)
 	if err != nil {
-		utils.LogFatal(err.Error())
+		return nil, nil, err
+	}
+	utils.LogSkipped(ctx, skipped)
+	if err := os.MkdirAll(output, 0755); err != nil {
+		return nil, skipped, utils.Errorf("创建输出目录失败: %w", err)
+	}
+
+	if plugin != nil && plugin.Supports(utils.PluginCapSegments) {
+		// 由插件提供切割方案
+		plan, err := plugin.Segments(ctx, 220980, aid, names)
+		if err != nil {
+			return nil, skipped, utils.Errorf("插件获取切割方案失败: %w", err)
+		}
+		for i := range task {
+			num := plan[names[i]]
+			task[i].Segments = &num
+		}
+	}
+
+	// 跳过清单中已还原的图片，force 为 true 时全部重新还原
+	results, pending := make([]utils.DecodeAndSaveResult, len(task)), make([]int, len(task))
+	for i := range pending {
+		pending[i] = i
+	}
+	if !force {
+		results, pending = completedRestores(ctx, output, task)
+	}
+	if len(pending) > 0 {
+		run := make([]utils.DecodeAndSaveTask, len(pending))
+		for i, idx := range pending {
+			run[i] = task[idx]
+		}
+		for i, res := range utils.BatchDecodeAndSave(ctx, 220980, aid, run, concurrency, events) {
+			results[pending[i]] = res
+		}
+	}
+	recordManifest(ctx, utils.StageRestore, restoredFiles(results))
+	return results, skipped, nil
+}
+
+// restoreTasks 构建还原任务，同时返回每张图片不带扩展名的文件名和跳过的文件，不创建输出目录
+func restoreTasks(input, output string, aid int, selection *utils.PageSelection, filter *utils.FileFilter, pageName *utils.NameTemplate) ([]utils.DecodeAndSaveTask, []string, []utils.SkippedFile, error) {
+	dirInfo, skipped, err := utils.GetDirInfo(input, filter)
+	if err != nil {
+		return nil, nil, nil, err
 	}
 	if len(dirInfo) > 1 {
-		utils.LogFatal("不支持非单层目录")
+		return nil, nil, nil, utils.Errorf("%w: %s 包含子目录，只支持单层目录", utils.ErrUnsupportedLayout, input)
+	}
+
+	// 记录页面选择前的页码
+	pages := make(map[string]int)
+	if len(dirInfo) > 0 {
+		for i, file := range dirInfo[0].Files {
+			pages[file] = i + 1
+		}
+	}
+	dirInfo = utils.FilterDirInfo(dirInfo, selection)
+	if len(dirInfo) == 0 {
+		return nil, nil, nil, utils.Errorf("没有符合页面选择的图片")
 	}
 
 	task := make([]utils.DecodeAndSaveTask, 0)
+	names := make([]string, 0, len(dirInfo[0].Files))
 	for _, file := range dirInfo[0].Files {
 		filename := filepath.Base(file)
 
 		// 获取不带扩展名的文件名
 		fileNameWithoutExt := strings.TrimSuffix(filename, filepath.Ext(filename))
+		names = append(names, fileNameWithoutExt)
 
 		// 构建新的文件名，使用 .jpeg 后缀
-		newFileName := fileNameWithoutExt + ".jpeg"
-
 		task = append(task, utils.DecodeAndSaveTask{
-			ImgSrcPath:      file,
-			DecodedSavePath: path.Join(output, newFileName),
+			ImgSrcPath: file,
+			DecodedSavePath: utils.PagePath(pageName, output, utils.NameValues{
+				Aid:     aid,
+				Chapter: 1,
+				Page:    pages[file],
+				Name:    fileNameWithoutExt,
+				Ext:     "jpeg",
+			}),
 		})
 	}
 
-	_ = utils.BatchDecodeAndSave(220980, aid, task, concurrency)
+	return task, names, skipped, nil
 }
@@ -0,0 +1,129 @@
+package mode
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"path/filepath"
+	"pickit/internal/utils"
+	"strconv"
+	"time"
+)
+
+// SyncSubscription 同步订阅：下载并还原新发布的章节，按需重新合成 PDF，返回本次同步的章节数。
+// 原图保存在 <output>/raw/<章节序号>/，还原后的图片保存在 <output>/images/<章节序号>/，PDF 为 <output>/<车牌号>.pdf。
+// client 为下载使用的 HTTP 客户端，为 nil 时按订阅的魔法创建
+func SyncSubscription(ctx context.Context, sub *utils.Subscription, provider utils.MetadataProvider, client *http.Client) (int, error) {
+	logger := utils.LoggerFrom(ctx)
+	album, newChapters, err := subscriptionChapters(ctx, sub, provider)
+	if err != nil {
+		return 0, err
+	}
+	if sub.Title == "" {
+		sub.Title = album.Title
+	}
+	sub.LastSync = time.Now()
+	if len(newChapters) == 0 {
+		logger.Info("订阅没有新章节",
+			utils.Int("aid", sub.Aid),
+			utils.Str("title", sub.Title))
+		return 0, nil
+	}
+
+	logger.Info("发现新章节",
+		utils.Int("aid", sub.Aid),
+		utils.Str("title", sub.Title),
+		utils.Int("new_chapters", len(newChapters)))
+
+	rawDir := path.Join(sub.Output, "raw")
+	imagesDir := path.Join(sub.Output, "images")
+	results, err := DownloadChapters(ctx, syncDownloadOptions(sub, client), newChapters)
+	if err != nil {
+		return 0, err
+	}
+
+	// 统计每个章节目录中下载失败的页数
+	failures := make(map[string]int)
+	for _, res := range results {
+		if res.Err != nil {
+			failures[filepath.Dir(res.Dist)]++
+		}
+	}
+
+	synced := 0
+	for _, chapter := range newChapters {
+		dir := path.Join(rawDir, strconv.Itoa(chapter.Sort))
+		if n := failures[filepath.Clean(dir)]; n > 0 {
+			logger.Warn("章节下载不完整，下次同步时重试",
+				utils.Int("aid", sub.Aid),
+				utils.Int("chapter", chapter.Sort),
+				utils.Int("failed", n))
+			continue
+		}
+
+		restored, _, err := RestoreImages(ctx, dir, path.Join(imagesDir, strconv.Itoa(chapter.Sort)), chapter.Id, sub.Concurrency, nil, nil, nil, nil, nil, false)
+		if err == nil {
+			for _, res := range restored {
+				if res.Err != nil {
+					err = utils.Errorf("还原 %s 失败: %w", res.ImgSrcPath, res.Err)
+					break
+				}
+			}
+		}
+		if err != nil {
+			logger.Error("章节还原失败，下次同步时重试",
+				utils.Int("aid", sub.Aid),
+				utils.Int("chapter", chapter.Sort),
+				utils.Err(err))
+			continue
+		}
+		sub.Chapters = append(sub.Chapters, chapter.Id)
+		synced++
+	}
+
+	if synced > 0 && sub.Pdf {
+		pdfPath := path.Join(sub.Output, strconv.Itoa(sub.Aid)+".pdf")
+		if _, err := CreatePDF(ctx, imagesDir, pdfPath, sub.Password, nil, nil, nil); err != nil {
+			return synced, utils.Errorf("合成 PDF 失败: %w", err)
+		}
+	}
+
+	logger.Info("订阅同步完成",
+		utils.Int("aid", sub.Aid),
+		utils.Str("title", sub.Title),
+		utils.Int("synced", synced),
+		utils.Int("pending", len(newChapters)-synced))
+	return synced, nil
+}
+
+// subscriptionChapters 获取本子信息和订阅中还没有同步的章节
+func subscriptionChapters(ctx context.Context, sub *utils.Subscription, provider utils.MetadataProvider) (*utils.AlbumMeta, []utils.ChapterMeta, error) {
+	if provider == nil {
+		return nil, nil, utils.Errorf("同步订阅需要配置本子信息接口")
+	}
+
+	album, err := provider.Album(ctx, sub.Aid)
+	if err != nil {
+		return nil, nil, utils.Errorf("获取本子信息失败: %w", err)
+	}
+
+	newChapters := make([]utils.ChapterMeta, 0)
+	for _, chapter := range album.Chapters {
+		if !sub.HasChapter(chapter.Id) {
+			newChapters = append(newChapters, chapter)
+		}
+	}
+	return album, newChapters, nil
+}
+
+// syncDownloadOptions 同步订阅时的下载选项，原图保存在 <output>/raw/<章节序号>/
+func syncDownloadOptions(sub *utils.Subscription, client *http.Client) DownloadOptions {
+	return DownloadOptions{
+		Cdn:          sub.Cdn,
+		Output:       path.Join(sub.Output, "raw"),
+		Proxy:        sub.Proxy,
+		Client:       client,
+		Concurrency:  sub.Concurrency,
+		FallbackExts: utils.DefaultFallbackExts,
+	}
+}
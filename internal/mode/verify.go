@@ -0,0 +1,259 @@
+package mode
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"pickit/internal/utils"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// VerifyOptions 校验选项
+type VerifyOptions struct {
+	Refetch     bool         // 重新下载有问题的原图
+	Restore     bool         // 重新还原有问题的图片
+	Concurrency int          // 并发数
+	Proxy       string       // 魔法，重新下载时使用
+	Client      *http.Client // 重新下载使用的 HTTP 客户端，为 nil 时按 Proxy 创建
+}
+
+// VerifyIssue 校验发现的一个问题
+type VerifyIssue struct {
+	Problem   string // missing、gap、hash_mismatch、corrupt
+	Stage     string // 生成文件的阶段，缺页时为相邻页面的阶段
+	Path      string // 有问题的文件，缺页时为缺少的页码所在的目录
+	Source    string // 清单中记录的来源
+	Page      int    // 缺少的页码，只有 gap 有
+	Err       error
+	Repaired  bool  // 已重新下载或还原
+	RepairErr error // 修复失败的原因
+}
+
+// VerifyResult 校验结果
+type VerifyResult struct {
+	Manifests int // 清单数量
+	Checked   int // 检查的文件数量
+	Issues    []VerifyIssue
+}
+
+// Repaired 返回已修复的问题数
+func (r *VerifyResult) Repaired() int {
+	count := 0
+	for _, issue := range r.Issues {
+		if issue.Repaired {
+			count++
+		}
+	}
+	return count
+}
+
+// Verify 按 dir 及其子目录中的清单检查文件是否缺失、页码是否连续、内容是否与清单一致以及图片能否解码。
+// 开启 Refetch、Restore 时只重新下载或还原有问题的文件，修复后更新清单
+func Verify(ctx context.Context, dir string, opts VerifyOptions) (*VerifyResult, error) {
+	dirs, err := utils.FindManifests(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(dirs) == 0 {
+		return nil, utils.Errorf("%s 中没有清单，清单由 download、restore、pdf 等命令生成", dir)
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+
+	result := &VerifyResult{Manifests: len(dirs)}
+	for _, d := range dirs {
+		manifest, err := utils.LoadManifest(d)
+		if err != nil {
+			return nil, err
+		}
+		result.Checked += len(manifest.Files)
+		issues, broken := checkManifest(ctx, manifest, opts.Concurrency)
+		if len(broken) > 0 && (opts.Refetch || opts.Restore) {
+			repairManifest(ctx, manifest, issues, broken, opts)
+		}
+		result.Issues = append(result.Issues, issues...)
+	}
+
+	utils.LoggerFrom(ctx).Info("校验完成",
+		utils.Str("dir", dir),
+		utils.Int("manifests", result.Manifests),
+		utils.Int("files", result.Checked),
+		utils.Int("issues", len(result.Issues)),
+		utils.Int("repaired", result.Repaired()))
+	return result, nil
+}
+
+// checkManifest 多线程检查清单中的文件，返回发现的问题和有问题的文件在清单中的下标（与问题一一对应，缺页为 -1）
+func checkManifest(ctx context.Context, manifest *utils.Manifest, workers int) ([]VerifyIssue, []int) {
+	problems := make([]string, len(manifest.Files))
+	errs := make([]error, len(manifest.Files))
+
+	taskCh := make(chan int, len(manifest.Files))
+	for i := range manifest.Files {
+		taskCh <- i
+	}
+	close(taskCh)
+
+	var wg sync.WaitGroup
+	for i := 0; i < min(workers, len(manifest.Files)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range taskCh {
+				if err := ctx.Err(); err != nil {
+					problems[idx], errs[idx] = utils.IssueMissing, err
+					continue
+				}
+				problems[idx], errs[idx] = manifest.CheckEntry(manifest.Files[idx])
+			}
+		}()
+	}
+	wg.Wait()
+
+	issues := make([]VerifyIssue, 0)
+	broken := make([]int, 0)
+	for i, problem := range problems {
+		if problem == "" {
+			continue
+		}
+		entry := manifest.Files[i]
+		issues = append(issues, VerifyIssue{
+			Problem: problem,
+			Stage:   entry.Stage,
+			Path:    manifest.Abs(entry.Path),
+			Source:  entry.Source,
+			Err:     errs[i],
+		})
+		broken = append(broken, i)
+	}
+
+	for _, gap := range manifestGaps(manifest) {
+		issues = append(issues, gap)
+		broken = append(broken, -1)
+	}
+	return issues, broken
+}
+
+// manifestGaps 按目录和阶段检查文件名为数字的页面，找出页码之间缺少的页面
+func manifestGaps(manifest *utils.Manifest) []VerifyIssue {
+	type group struct{ stage, dir string }
+	pages := make(map[group][]int)
+	order := make([]group, 0)
+	for _, entry := range manifest.Files {
+		if entry.Stage == utils.StagePdf {
+			continue
+		}
+		page, err := strconv.Atoi(trimExt(entry.Path))
+		if err != nil {
+			continue
+		}
+		g := group{entry.Stage, path.Dir(entry.Path)}
+		if _, ok := pages[g]; !ok {
+			order = append(order, g)
+		}
+		pages[g] = append(pages[g], page)
+	}
+
+	issues := make([]VerifyIssue, 0)
+	for _, g := range order {
+		nums := pages[g]
+		sort.Ints(nums)
+		for i := 1; i < len(nums); i++ {
+			for missing := nums[i-1] + 1; missing < nums[i]; missing++ {
+				issues = append(issues, VerifyIssue{
+					Problem: utils.IssueGap,
+					Stage:   g.stage,
+					Path:    manifest.Abs(g.dir),
+					Page:    missing,
+					Err:     utils.Errorf("缺少第 %d 页", missing),
+				})
+			}
+		}
+	}
+	return issues
+}
+
+// repairManifest 重新下载或还原有问题的文件，先下载再还原，修复后更新清单。缺页和 PDF 无法修复
+func repairManifest(ctx context.Context, manifest *utils.Manifest, issues []VerifyIssue, broken []int, opts VerifyOptions) {
+	downloads := make([]utils.DownloadTask, 0)
+	downloadIssues := make([]int, 0)
+	restores := make([]utils.DecodeAndSaveTask, 0)
+	restoreIssues := make([]int, 0)
+	for i, idx := range broken {
+		if idx < 0 {
+			continue
+		}
+		entry := manifest.Files[idx]
+		file := manifest.Abs(entry.Path)
+		switch {
+		case entry.Stage == utils.StageDownload && opts.Refetch:
+			downloads = append(downloads, utils.DownloadTask{Url: entry.Source, Dist: file})
+			downloadIssues = append(downloadIssues, i)
+		case entry.Stage == utils.StageRestore && opts.Restore:
+			if entry.Segments == nil {
+				issues[i].RepairErr = utils.Errorf("清单中没有切割刀数，无法重新还原")
+				continue
+			}
+			restores = append(restores, utils.DecodeAndSaveTask{ImgSrcPath: manifest.Abs(entry.Source), DecodedSavePath: file, Segments: entry.Segments})
+			restoreIssues = append(restoreIssues, i)
+		}
+	}
+
+	if len(downloads)+len(restores) == 0 {
+		return
+	}
+	for _, t := range downloads {
+		if err := os.MkdirAll(filepath.Dir(t.Dist), 0755); err != nil {
+			utils.LoggerFrom(ctx).Warn("创建输出目录失败", utils.Str("dir", filepath.Dir(t.Dist)), utils.Err(err))
+		}
+	}
+	for _, t := range restores {
+		if err := os.MkdirAll(filepath.Dir(t.DecodedSavePath), 0755); err != nil {
+			utils.LoggerFrom(ctx).Warn("创建输出目录失败", utils.Str("dir", filepath.Dir(t.DecodedSavePath)), utils.Err(err))
+		}
+	}
+
+	if len(downloads) > 0 {
+		downloader := &utils.Downloader{Client: opts.Client, Proxy: opts.Proxy, MaxRetries: 6}
+		for i, res := range downloader.BatchDownload(ctx, downloads, opts.Concurrency, nil) {
+			issue := &issues[downloadIssues[i]]
+			issue.RepairErr = res.Err
+			if res.Err == nil {
+				repairEntry(manifest, issue, utils.StageDownload, res.Url, res.Dist, nil)
+			}
+		}
+	}
+	if len(restores) > 0 {
+		for i, res := range utils.BatchDecodeAndSave(ctx, 220980, 0, restores, opts.Concurrency, nil) {
+			issue := &issues[restoreIssues[i]]
+			issue.RepairErr = res.Err
+			if res.Err == nil {
+				repairEntry(manifest, issue, utils.StageRestore, res.ImgSrcPath, res.DecodedSavePath, &res.Segments)
+			}
+		}
+	}
+	if err := manifest.Save(); err != nil {
+		utils.LoggerFrom(ctx).Warn("更新清单失败", utils.Str("dir", manifest.Dir()), utils.Err(err))
+	}
+}
+
+// repairEntry 检查重新生成的文件能否解码，通过后更新清单
+func repairEntry(manifest *utils.Manifest, issue *VerifyIssue, stage, source, file string, segments *int) {
+	entry, err := manifest.NewEntry(stage, source, file, segments)
+	if err == nil {
+		if problem, checkErr := manifest.CheckEntry(entry); problem != "" {
+			err = checkErr
+		}
+	}
+	if err != nil {
+		issue.RepairErr = err
+		return
+	}
+	manifest.Add(entry)
+	issue.Repaired = true
+}
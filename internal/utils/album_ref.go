@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// AlbumRefKind 编号类型
+type AlbumRefKind string
+
+const (
+	AlbumRefAlbum   AlbumRefKind = "album"   // 本子
+	AlbumRefChapter AlbumRefKind = "chapter" // 章节
+)
+
+// AlbumRef 解析后的本子或章节编号
+type AlbumRef struct {
+	Kind AlbumRefKind
+	Id   int
+}
+
+func (r AlbumRef) String() string {
+	return fmt.Sprintf("%s:%d", r.Kind, r.Id)
+}
+
+var (
+	bareIdPattern = regexp.MustCompile(`^(?i:jm)?\s*(\d+)$`)
+	urlIdPattern  = regexp.MustCompile(`^/(album|photo)/(\d+)(?:/.*)?$`)
+	urlAnyPattern = regexp.MustCompile(`/(?:album|photo)/\d+`)
+)
+
+/*
+ParseAlbumRef 解析车牌号。
+支持的格式:
+
+	123456
+	JM123456、jm123456
+	https://<站点>/album/123456/<标题>    本子
+	https://<站点>/photo/123456            章节
+
+无法唯一确定编号的输入会返回错误。
+*/
+func ParseAlbumRef(input string) (AlbumRef, error) {
+	s := strings.TrimSpace(input)
+	if s == "" {
+		return AlbumRef{}, Errorf("车牌号不能为空")
+	}
+
+	// 纯数字或 JM 前缀
+	if m := bareIdPattern.FindStringSubmatch(s); m != nil {
+		id, err := parsePositiveId(m[1])
+		if err != nil {
+			return AlbumRef{}, Errorf("无效的车牌号 %q: %w", input, err)
+		}
+		return AlbumRef{Kind: AlbumRefAlbum, Id: id}, nil
+	}
+
+	// 既不是编号也不像链接
+	if !strings.ContainsAny(s, "./") {
+		return AlbumRef{}, Errorf("无法识别的车牌号 %q，请传入数字、JM 编号或本子/章节链接", input)
+	}
+
+	// 链接，允许省略协议
+	raw := s
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return AlbumRef{}, Errorf("无法识别的车牌号 %q，请传入数字、JM 编号或本子/章节链接", input)
+	}
+
+	if len(urlAnyPattern.FindAllString(u.Path, -1)) > 1 {
+		return AlbumRef{}, Errorf("链接 %q 中包含多个编号，无法确定车牌号", input)
+	}
+	m := urlIdPattern.FindStringSubmatch(u.Path)
+	if m == nil {
+		return AlbumRef{}, Errorf("无法从链接 %q 中识别车牌号，链接路径应为 /album/<编号> 或 /photo/<编号>", input)
+	}
+	id, err := parsePositiveId(m[2])
+	if err != nil {
+		return AlbumRef{}, Errorf("无效的车牌号 %q: %w", input, err)
+	}
+
+	kind := AlbumRefAlbum
+	if m[1] == "photo" {
+		kind = AlbumRefChapter
+	}
+	return AlbumRef{Kind: kind, Id: id}, nil
+}
+
+func parsePositiveId(s string) (int, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, Errorf("编号超出范围")
+	}
+	if id <= 0 {
+		return 0, Errorf("编号必须大于 0")
+	}
+	return id, nil
+}
@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 导出格式
+const (
+	ExportRaw    = "raw"    // 只下载原图
+	ExportImages = "images" // 下载并还原
+	ExportPdf    = "pdf"    // 下载、还原并合成 PDF
+)
+
+/*
+BatchFile 批量任务文件，支持 JSON 和 YAML，按扩展名 .yaml、.yml 识别 YAML，字段名相同。
+
+	{
+	  "concurrency": 16,
+	  "proxy": "http://127.0.0.1:7890",
+	  "defaults": {"cdn": "https://cdn", "export": "pdf"},
+	  "albums": [
+	    {"aid": "JM123456", "output": "out/123456"},
+	    {"aid": "https://<站点>/album/654321", "output": "out/654321", "chapters": "1-3", "export": "images"},
+	    {"aid": "777", "cdn": "https://cdn2", "count": 30, "output": "out/777", "password": "pwd"}
+	  ]
+	}
+
+对应的 YAML:
+
+	concurrency: 16
+	defaults:
+	  cdn: https://cdn
+	  export: pdf
+	albums:
+	  - aid: JM123456
+	    output: out/123456
+	  - aid: "777"
+	    count: 30
+	    output: out/777
+
+albums 中没有填写的字段使用 defaults 中的值。
+*/
+type BatchFile struct {
+	Concurrency int          `json:"concurrency" yaml:"concurrency"`
+	Proxy       string       `json:"proxy" yaml:"proxy"`
+	Defaults    BatchAlbum   `json:"defaults" yaml:"defaults"`
+	Albums      []BatchAlbum `json:"albums" yaml:"albums"`
+}
+
+// BatchAlbum 批量任务中的单个本子
+type BatchAlbum struct {
+	Aid      string `json:"aid" yaml:"aid"`           // 车牌号，支持数字、JM 编号和链接
+	Cdn      string `json:"cdn" yaml:"cdn"`           // 图片域名地址
+	Count    int    `json:"count" yaml:"count"`       // 图片数量，为 0 时通过本子信息获取
+	Output   string `json:"output" yaml:"output"`     // 输出路径
+	Export   string `json:"export" yaml:"export"`     // 导出格式: raw、images、pdf
+	Chapters string `json:"chapters" yaml:"chapters"` // 章节范围
+	Password string `json:"password" yaml:"password"` // PDF 密码
+}
+
+// LoadBatchFile 读取批量任务文件，并把 defaults 合并到每个本子中
+func LoadBatchFile(path string) (*BatchFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, Errorf("读取批量任务文件失败: %w", err)
+	}
+
+	var file BatchFile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &file)
+	default:
+		err = json.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return nil, Errorf("解析批量任务文件失败: %w", err)
+	}
+	if len(file.Albums) == 0 {
+		return nil, Errorf("批量任务文件中没有本子: %s", path)
+	}
+
+	for i := range file.Albums {
+		a := &file.Albums[i]
+		if a.Cdn == "" {
+			a.Cdn = file.Defaults.Cdn
+		}
+		if a.Export == "" {
+			a.Export = file.Defaults.Export
+		}
+		if a.Export == "" {
+			a.Export = ExportRaw
+		}
+		if a.Password == "" {
+			a.Password = file.Defaults.Password
+		}
+		if a.Chapters == "" {
+			a.Chapters = file.Defaults.Chapters
+		}
+		if a.Output == "" && file.Defaults.Output != "" && a.Aid != "" {
+			// 默认输出到 <defaults.output>/<车牌号>
+			if ref, err := ParseAlbumRef(a.Aid); err == nil {
+				a.Output = filepath.Join(file.Defaults.Output, fmt.Sprint(ref.Id))
+			}
+		}
+
+		switch a.Export {
+		case ExportRaw, ExportImages, ExportPdf:
+		default:
+			return nil, Errorf("第 %d 个本子的导出格式无效: %s，可选 raw、images、pdf", i+1, a.Export)
+		}
+	}
+	return &file, nil
+}
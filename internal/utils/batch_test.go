@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadBatchFileYAMLAndJSON(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"jobs.json": `{
+  "concurrency": 4,
+  "defaults": {"cdn": "https://cdn", "export": "pdf", "output": "out"},
+  "albums": [
+    {"aid": "300", "count": 3},
+    {"aid": "JM102", "output": "other", "export": "raw", "chapters": "1-2"}
+  ]
+}`,
+		"jobs.yaml": `concurrency: 4
+defaults:
+  cdn: https://cdn
+  export: pdf
+  output: out
+albums:
+  - aid: 300
+    count: 3
+  - aid: JM102
+    output: other
+    export: raw
+    chapters: 1-2
+`,
+	}
+
+	loaded := make(map[string]*BatchFile)
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		file, err := LoadBatchFile(path)
+		if err != nil {
+			t.Fatalf("LoadBatchFile(%s): %v", name, err)
+		}
+		loaded[name] = file
+	}
+
+	yml := loaded["jobs.yaml"]
+	if !reflect.DeepEqual(loaded["jobs.json"], yml) {
+		t.Errorf("yaml = %+v\njson = %+v", yml, loaded["jobs.json"])
+	}
+	want := BatchAlbum{Aid: "300", Cdn: "https://cdn", Count: 3, Output: filepath.Join("out", "300"), Export: ExportPdf}
+	if yml.Concurrency != 4 || len(yml.Albums) != 2 || yml.Albums[0] != want {
+		t.Errorf("albums = %+v", yml.Albums)
+	}
+	if a := yml.Albums[1]; a.Output != "other" || a.Export != ExportRaw || a.Chapters != "1-2" {
+		t.Errorf("second album = %+v", a)
+	}
+}
+
+func TestLoadBatchFileInvalid(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]string{
+		"empty.yml":   "albums: []\n",
+		"broken.yaml": "albums: [\n",
+		"export.yml":  "albums:\n  - aid: 1\n    export: zip\n",
+	}
+	for name, content := range tests {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadBatchFile(path); err == nil {
+			t.Errorf("LoadBatchFile(%s): want error", name)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+/*
+Config 配置文件（JSON），默认位于 <用户配置目录>/pickit/config.json。
+
+	{
+	  "profile": "home",
+	  "defaults": {"concurrency": 16, "download.output": "/data/jm"},
+	  "profiles": {
+	    "home": {"proxy": "http://127.0.0.1:7890", "cdn": "https://cdn-a"},
+	    "vps":  {"cdn": "https://cdn-b", "concurrency": 32}
+	  }
+	}
+
+键为命令行参数的长名称，对所有带该参数的命令生效；也可以加上命令路径前缀（子命令用 . 连接），
+例如 "download.output"、"subscribe.add.output"，只对该命令生效，且优先于不带前缀的键。
+profile 为未指定 --profile 时使用的配置。
+*/
+type Config struct {
+	Profile  string                   `json:"profile"`
+	Defaults ConfigSection            `json:"defaults"`
+	Profiles map[string]ConfigSection `json:"profiles"`
+}
+
+// ConfigSection 一组参数值
+type ConfigSection map[string]any
+
+// DefaultConfigPath 默认的配置文件: <用户配置目录>/pickit/config.json
+func DefaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return filepath.Join(".", "config.json")
+	}
+	return filepath.Join(dir, "pickit", "config.json")
+}
+
+// LoadConfig 读取配置文件，文件不存在时返回空配置
+func LoadConfig(path string) (*Config, error) {
+	config := &Config{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return config, nil
+		}
+		return nil, Errorf("读取配置文件失败: %w", err)
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, Errorf("解析配置文件失败: %w", err)
+	}
+	return config, nil
+}
+
+// Section 返回 profile 对应的配置，profile 不存在时返回错误
+func (c *Config) Section(profile string) (ConfigSection, error) {
+	if profile == "" {
+		return nil, nil
+	}
+	section, ok := c.Profiles[profile]
+	if !ok {
+		return nil, Errorf("配置文件中没有 profile: %s", profile)
+	}
+	return section, nil
+}
+
+// Lookup 查找命令参数的值，先查找带命令前缀的键，再查找参数名。command 为用 . 连接的命令路径
+func (s ConfigSection) Lookup(command, flag string) (string, bool) {
+	keys := []string{flag}
+	if command != "" {
+		keys = []string{command + "." + flag, flag}
+	}
+	for _, key := range keys {
+		if v, ok := s[key]; ok {
+			return configValueString(v), true
+		}
+	}
+	return "", false
+}
+
+// configValueString 把配置值转换为命令行参数的字符串形式，数组用逗号连接
+func configValueString(v any) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	case []any:
+		parts := make([]string, 0, len(val))
+		for _, item := range val {
+			parts = append(parts, configValueString(item))
+		}
+		return strings.Join(parts, ",")
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(val)
+	}
+}
@@ -1,8 +1,12 @@
 package utils
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"sort"
@@ -42,63 +46,250 @@ func ProcessPaths(input, output string) (string, string) {
 	return input, output
 }
 
-func GetDirInfo(dir string) ([]DirInfo, error) {
-	entries, err := os.ReadDir(dir)
-	if err != nil {
-		return nil, fmt.Errorf("读取目录失败: %w", err)
+// 跳过文件的原因
+const (
+	SkipHidden   = "hidden"    // 以 . 开头的文件和目录
+	SkipTemp     = "temp"      // 未完成的下载、临时文件和系统生成的文件，如 .part、Thumbs.db
+	SkipExcluded = "excluded"  // 不符合文件过滤规则
+	SkipNotImage = "not_image" // 扩展名或内容不是图片
+	SkipLayout   = "layout"    // 有章节目录时根目录中的文件，以及章节目录中的子目录
+)
+
+// SkippedFile 读取图片目录时跳过的文件或目录
+type SkippedFile struct {
+	Path   string
+	Reason string
+	IsDir  bool
+}
+
+// Describe 返回跳过原因的说明
+func (s SkippedFile) Describe() string {
+	switch s.Reason {
+	case SkipHidden:
+		return T("隐藏文件")
+	case SkipTemp:
+		return T("临时文件")
+	case SkipExcluded:
+		return T("不符合文件过滤规则")
+	case SkipNotImage:
+		return T("不是图片")
+	case SkipLayout:
+		if s.IsDir {
+			return T("只支持两层目录")
+		}
+		return T("包含章节目录时根目录中的文件会被忽略")
 	}
+	return s.Reason
+}
 
-	// 判断是否为扁平结构
-	hasSubDir := false
-	for _, entry := range entries {
-		if entry.IsDir() {
-			hasSubDir = true
-			break
+// imageExts 按内容识别前先按扩展名筛选的图片格式，没有扩展名的文件只按内容识别
+var imageExts = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".gif":  true,
+	".webp": true,
+	"":      true,
+}
+
+// imageTypes 按文件头识别的图片格式，与能解码的格式一致
+var imageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// tempExts 未完成的下载和编辑器、浏览器留下的临时文件
+var tempExts = map[string]bool{
+	".part":       true,
+	".partial":    true,
+	".crdownload": true,
+	".download":   true,
+	".tmp":        true,
+	".temp":       true,
+	".swp":        true,
+	".bak":        true,
+}
+
+// systemFiles 系统生成的文件，按小写比较
+var systemFiles = map[string]bool{
+	"thumbs.db":   true,
+	"ehthumbs.db": true,
+	"desktop.ini": true,
+}
+
+// FileFilter 读取图片目录时的文件过滤规则，语法同 path.Match，同时匹配文件名和相对于图片目录的路径（用 / 分隔）
+type FileFilter struct {
+	Include []string // 只读取匹配的文件，为空时读取所有图片
+	Exclude []string // 跳过匹配的文件和章节目录
+}
+
+// NewFileFilter 检查匹配规则后创建文件过滤规则，两者都为空时返回 nil
+func NewFileFilter(include, exclude []string) (*FileFilter, error) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil, nil
+	}
+	for _, pattern := range append(append([]string{}, include...), exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("%s: %w", pattern, err)
 		}
 	}
+	return &FileFilter{Include: include, Exclude: exclude}, nil
+}
 
-	result := make([]DirInfo, 0)
+// excluded 判断文件或目录是否被过滤，rel 为相对于图片目录的路径。Include 只对文件生效
+func (f *FileFilter) excluded(rel string, dir bool) bool {
+	if f == nil {
+		return false
+	}
+	if matchAny(f.Exclude, rel) {
+		return true
+	}
+	return !dir && len(f.Include) > 0 && !matchAny(f.Include, rel)
+}
+
+// matchAny 判断路径或文件名是否匹配任意一个规则
+func matchAny(patterns []string, rel string) bool {
+	base := path.Base(rel)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, rel); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, base); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDirInfo 读取图片目录，没有子目录时为单层目录，否则每个子目录作为一个章节。
+// 隐藏文件、临时文件、不符合 filter（可以为 nil）的文件以及扩展名或内容不是图片的文件会被跳过，与不支持的目录层级一起在 skipped 中返回。
+// 清单是 pickit 自己的文件，直接忽略，不出现在 skipped 中
+func GetDirInfo(dir string, filter *FileFilter) (result []DirInfo, skipped []SkippedFile, err error) {
+	folders, files, skipped, err := scanDir(dir, "", filter)
+	if err != nil {
+		return nil, nil, Errorf("读取目录失败: %w", err)
+	}
 
-	if !hasSubDir {
+	result = make([]DirInfo, 0)
+	if len(folders) == 0 {
 		// 单层目录处理
-		files := make([]string, 0, len(entries))
-		for _, entry := range entries {
-			if !entry.IsDir() {
-				files = append(files, filepath.Join(dir, entry.Name()))
-			}
+		images, notImages := sniffImages(files)
+		skipped = append(skipped, notImages...)
+		SortNumericPaths(images)
+		return append(result, DirInfo{"", images}), skipped, nil
+	}
+
+	// 多层目录处理，根目录中的文件会被忽略
+	for _, file := range files {
+		skipped = append(skipped, SkippedFile{Path: file, Reason: SkipLayout})
+	}
+	SortNumericDirs(folders)
+
+	for _, folder := range folders {
+		subDirs, subFiles, subSkipped, err := scanDir(dir, folder, filter)
+		if err != nil {
+			return nil, nil, Errorf("读取子目录 %s 失败: %w", folder, err)
 		}
-		SortNumericPaths(files)
-		result = append(result, DirInfo{"", files})
-	} else {
-		// 多层目录处理
-		folders := make([]string, 0, len(entries))
-		for _, entry := range entries {
-			if entry.IsDir() {
-				folders = append(folders, entry.Name())
-			}
+		skipped = append(skipped, subSkipped...)
+		for _, sub := range subDirs {
+			skipped = append(skipped, SkippedFile{Path: filepath.Join(dir, folder, sub), Reason: SkipLayout, IsDir: true})
 		}
 
-		SortNumericDirs(folders)
+		images, notImages := sniffImages(subFiles)
+		skipped = append(skipped, notImages...)
+		SortNumericPaths(images)
+		result = append(result, DirInfo{folder, images})
+	}
+
+	return result, skipped, nil
+}
+
+// LogSkipped 逐个记录跳过的文件，不是图片和目录层级不支持的文件可能是遗漏的页面，使用 Warn 级别
+func LogSkipped(ctx context.Context, skipped []SkippedFile) {
+	logger := LoggerFrom(ctx)
+	for _, s := range skipped {
+		if s.Reason == SkipNotImage || s.Reason == SkipLayout {
+			logger.Warn("跳过文件", Str("path", s.Path), Str("reason", s.Reason))
+		} else {
+			logger.Info("跳过文件", Str("path", s.Path), Str("reason", s.Reason))
+		}
+	}
+}
+
+// scanDir 读取 root 中的 rel 目录，按名称和过滤规则分出子目录名和文件路径，此时还没有检查文件内容
+func scanDir(root, rel string, filter *FileFilter) (dirs, files []string, skipped []SkippedFile, err error) {
+	entries, err := os.ReadDir(filepath.Join(root, rel))
+	if err != nil {
+		return nil, nil, nil, err
+	}
 
-		for _, folder := range folders {
-			subEntries, err := os.ReadDir(filepath.Join(dir, folder))
-			if err != nil {
-				return nil, fmt.Errorf("读取子目录 %s 失败: %w", folder, err)
-			}
+	dirs = make([]string, 0)
+	files = make([]string, 0, len(entries))
+	skipped = make([]SkippedFile, 0)
+	for _, entry := range entries {
+		name := entry.Name()
+		if name == ManifestName {
+			continue
+		}
+		full := filepath.Join(root, rel, name)
+		reason := skipName(name, entry.IsDir())
+		if reason == "" && filter.excluded(path.Join(filepath.ToSlash(rel), name), entry.IsDir()) {
+			reason = SkipExcluded
+		}
+		switch {
+		case reason != "":
+			skipped = append(skipped, SkippedFile{Path: full, Reason: reason, IsDir: entry.IsDir()})
+		case entry.IsDir():
+			dirs = append(dirs, name)
+		default:
+			files = append(files, full)
+		}
+	}
+	return dirs, files, skipped, nil
+}
 
-			files := make([]string, 0, len(subEntries))
-			for _, entry := range subEntries {
-				if !entry.IsDir() {
-					files = append(files, filepath.Join(dir, folder, entry.Name()))
-				}
-			}
+// skipName 按名称判断是否为隐藏文件或临时文件，不需要跳过时返回空字符串
+func skipName(name string, dir bool) string {
+	if strings.HasPrefix(name, ".") {
+		return SkipHidden
+	}
+	if dir {
+		return ""
+	}
+	lower := strings.ToLower(name)
+	if systemFiles[lower] || tempExts[filepath.Ext(lower)] || strings.HasPrefix(name, "~$") || strings.HasSuffix(name, "~") {
+		return SkipTemp
+	}
+	return ""
+}
 
-			SortNumericPaths(files)
-			result = append(result, DirInfo{folder, files})
+// sniffImages 按扩展名和文件头识别图片，返回图片和被跳过的文件
+func sniffImages(files []string) ([]string, []SkippedFile) {
+	images := make([]string, 0, len(files))
+	skipped := make([]SkippedFile, 0)
+	for _, file := range files {
+		if imageExts[strings.ToLower(filepath.Ext(file))] && isImageContent(file) {
+			images = append(images, file)
+			continue
 		}
+		skipped = append(skipped, SkippedFile{Path: file, Reason: SkipNotImage})
+	}
+	return images, skipped
+}
+
+// isImageContent 按文件头判断文件内容是否为支持的图片格式
+func isImageContent(file string) bool {
+	f, err := os.Open(file)
+	if err != nil {
+		return false
 	}
+	defer f.Close()
 
-	return result, nil
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(f, head)
+	return imageTypes[http.DetectContentType(head[:n])]
 }
 
 // SortNumericPaths 对路径切片进行数字优先排序
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package utils
+
+import "errors"
+
+// diskFree 当前平台无法获取剩余空间
+func diskFree(dir string) (int64, error) {
+	return 0, errors.ErrUnsupported
+}
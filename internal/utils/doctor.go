@@ -0,0 +1,313 @@
+package utils
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// 诊断项的结果
+const (
+	CheckPass = "pass" // 通过
+	CheckFail = "fail" // 未通过
+	CheckSkip = "skip" // 不适用，如没有配置代理或不是 HTTPS
+)
+
+// 诊断项
+const (
+	CheckProxy     = "proxy"      // 代理是否可以连接
+	CheckDNS       = "dns"        // CDN 域名解析
+	CheckTLS       = "tls"        // 与 CDN 的 TLS 握手
+	CheckHTTP      = "http"       // 示例页面的状态码
+	CheckDiskWrite = "disk_write" // 输出目录是否可写
+	CheckDiskSpace = "disk_space" // 输出目录所在磁盘的剩余空间
+)
+
+// CheckResult 单项诊断的结果
+type CheckResult struct {
+	Name     string        // 诊断项，如 proxy、dns
+	Target   string        // 检查的对象，如代理地址、域名、目录
+	Status   string        // pass、fail、skip
+	Detail   string        // 检查到的情况，如解析到的地址、状态码
+	Hint     string        // 未通过时的处理建议
+	Err      error         // 未通过的原因
+	Duration time.Duration // 检查耗时
+}
+
+// Failed 是否未通过
+func (r CheckResult) Failed() bool {
+	return r.Status == CheckFail
+}
+
+// CheckProxyReachable 检查能否连接到代理，proxy 为空时跳过
+func CheckProxyReachable(ctx context.Context, proxy string) CheckResult {
+	res := CheckResult{Name: CheckProxy, Target: proxy}
+	if proxy == "" {
+		res.Status, res.Detail = CheckSkip, T("没有配置代理")
+		return res
+	}
+	proxyURL, err := url.Parse(proxy)
+	if err != nil || proxyURL.Host == "" {
+		if err == nil {
+			err = Errorf("缺少主机名: %s", proxy)
+		}
+		return fail(res, err, T("代理地址格式错误，应为 http://127.0.0.1:7890 或 socks5://127.0.0.1:1080"))
+	}
+
+	addr := hostPort(proxyURL)
+	res.Target = addr
+	start := time.Now()
+	conn, err := (&net.Dialer{Timeout: 5 * time.Second}).DialContext(ctx, "tcp", addr)
+	res.Duration = time.Since(start)
+	if err != nil {
+		return fail(res, err, T("无法连接代理，确认代理软件已启动并监听该端口"))
+	}
+	conn.Close()
+	res.Status, res.Detail = CheckPass, fmt.Sprintf(T("已连接，耗时 %s"), res.Duration.Round(time.Millisecond))
+	return res
+}
+
+// CheckDNSResolve 在本地解析 CDN 域名。使用代理时由代理解析域名，本地解析失败不影响下载，结果为跳过
+func CheckDNSResolve(ctx context.Context, host string, proxied bool) CheckResult {
+	res := CheckResult{Name: CheckDNS, Target: host}
+	if net.ParseIP(host) != nil {
+		res.Status, res.Detail = CheckSkip, T("地址不是域名，无需解析")
+		return res
+	}
+
+	start := time.Now()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	res.Duration = time.Since(start)
+	if err != nil {
+		if proxied {
+			res.Status, res.Err = CheckSkip, err
+			res.Detail = T("本地无法解析，使用代理时由代理解析域名")
+			return res
+		}
+		return fail(res, err, T("域名无法解析，检查 --cdn 是否正确、网络和 DNS 设置，或通过 --proxy 使用代理"))
+	}
+	res.Status, res.Detail = CheckPass, fmt.Sprint(addrs)
+	return res
+}
+
+// CheckPage 用下载使用的 HTTP 客户端请求示例页面，返回 TLS 握手和状态码两项结果。
+// 原地址 404 时依次请求 fallbacks，与下载时的选择顺序一致
+func CheckPage(ctx context.Context, client *http.Client, pageUrl string, fallbacks ...string) (tlsRes, httpRes CheckResult) {
+	tlsRes = CheckResult{Name: CheckTLS, Status: CheckSkip}
+	httpRes = CheckResult{Name: CheckHTTP, Target: pageUrl}
+	if u, err := url.Parse(pageUrl); err == nil {
+		tlsRes.Target = hostPort(u)
+		if u.Scheme != "https" {
+			tlsRes.Detail = T("不是 HTTPS 地址")
+		}
+	}
+
+	var status int
+	var size int64
+	var err error
+	for _, candidate := range append([]string{pageUrl}, fallbacks...) {
+		httpRes.Target = candidate
+		status, size, err = tracedGet(ctx, client, candidate, &tlsRes)
+		if status != http.StatusNotFound {
+			break
+		}
+	}
+	if status == http.StatusNotFound {
+		httpRes.Target = pageUrl
+	}
+	if tlsRes.Status == CheckSkip && tlsRes.Detail == "" {
+		tlsRes.Detail = T("没有建立连接")
+	}
+
+	switch {
+	case err != nil && status == 0:
+		httpRes.Status, httpRes.Err = CheckFail, err
+		httpRes.Hint = requestHint(err, tlsRes)
+	case status == http.StatusOK:
+		httpRes.Status = CheckPass
+		httpRes.Detail = fmt.Sprintf(T("状态码 %d，大小 %d B"), status, size)
+		if httpRes.Target != pageUrl {
+			httpRes.Detail += T("，使用备选格式")
+		}
+	default:
+		httpRes.Status, httpRes.Err = CheckFail, err
+		httpRes.Detail = fmt.Sprintf(T("状态码 %d"), status)
+		httpRes.Hint = statusHint(err)
+	}
+	return tlsRes, httpRes
+}
+
+// tracedGet 请求 pageUrl 并记录 TLS 握手的结果，只读取响应头和内容长度
+func tracedGet(ctx context.Context, client *http.Client, pageUrl string, tlsRes *CheckResult) (int, int64, error) {
+	var handshakeStart time.Time
+	trace := &httptrace.ClientTrace{
+		TLSHandshakeStart: func() { handshakeStart = time.Now() },
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			tlsRes.Duration = time.Since(handshakeStart)
+			if err != nil {
+				*tlsRes = fail(*tlsRes, err, tlsHint(err))
+				return
+			}
+			tlsRes.Status, tlsRes.Err, tlsRes.Hint = CheckPass, nil, ""
+			tlsRes.Detail = tls.VersionName(state.Version)
+			if len(state.PeerCertificates) > 0 {
+				cert := state.PeerCertificates[0]
+				name := cert.Subject.CommonName
+				if name == "" && len(cert.DNSNames) > 0 {
+					name = cert.DNSNames[0]
+				}
+				tlsRes.Detail += fmt.Sprintf(T("，证书 %s 有效期至 %s"), name, cert.NotAfter.Format(time.DateOnly))
+			}
+		},
+	}
+
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace), http.MethodGet, pageUrl, nil)
+	if err != nil {
+		return 0, 0, Errorf("创建HTTP请求失败: %w (url=%s)", err, pageUrl)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, 0, Errorf("HTTP请求失败: %w (url=%s)", err, pageUrl)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, 0, newHTTPError(pageUrl, resp)
+	}
+	size := resp.ContentLength
+	if size < 0 {
+		size, _ = io.Copy(io.Discard, resp.Body)
+	}
+	return resp.StatusCode, size, nil
+}
+
+// CheckDirWritable 在输出目录中创建并删除一个临时文件。目录不存在时检查最近的已存在的上级目录，下载时会自动创建
+func CheckDirWritable(dir string) CheckResult {
+	res := CheckResult{Name: CheckDiskWrite, Target: existingParent(dir)}
+	start := time.Now()
+	f, err := os.CreateTemp(res.Target, ".pickit-doctor-*")
+	if err != nil {
+		return fail(res, err, T("没有写入权限，检查目录权限或换一个输出目录"))
+	}
+	_, err = f.Write([]byte("pickit"))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	os.Remove(f.Name())
+	res.Duration = time.Since(start)
+	if err != nil {
+		return fail(res, err, T("写入失败，磁盘可能已满或为只读"))
+	}
+	res.Status, res.Detail = CheckPass, T("可以写入")
+	return res
+}
+
+// CheckDiskFree 检查输出目录所在磁盘的剩余空间是否不少于 minFree 字节，当前平台无法获取时跳过
+func CheckDiskFree(dir string, minFree int64) CheckResult {
+	res := CheckResult{Name: CheckDiskSpace, Target: existingParent(dir)}
+	free, err := diskFree(res.Target)
+	if errors.Is(err, errors.ErrUnsupported) {
+		res.Status, res.Detail = CheckSkip, T("当前平台无法获取剩余空间")
+		return res
+	}
+	if err != nil {
+		return fail(res, err, T("无法获取剩余空间，确认目录存在且可以访问"))
+	}
+	res.Detail = fmt.Sprintf(T("剩余 %d MB"), free>>20)
+	if free < minFree {
+		res.Status = CheckFail
+		res.Hint = fmt.Sprintf(T("剩余空间少于 %d MB，清理磁盘或换一个输出目录"), minFree>>20)
+		return res
+	}
+	res.Status = CheckPass
+	return res
+}
+
+// fail 标记诊断未通过
+func fail(res CheckResult, err error, hint string) CheckResult {
+	res.Status, res.Err, res.Hint = CheckFail, err, hint
+	return res
+}
+
+// tlsHint 根据 TLS 握手的错误给出建议
+func tlsHint(err error) string {
+	var certErr *tls.CertificateVerificationError
+	var hostErr x509.HostnameError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &hostErr):
+		return T("证书与域名不匹配，检查 --cdn 是否正确，或代理是否篡改了连接")
+	case errors.As(err, &certErr):
+		return T("证书无法验证，检查系统时间和根证书，或代理是否替换了证书")
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return T("握手超时，连接可能被干扰，尝试通过 --proxy 使用代理")
+	}
+	return T("握手失败，连接可能被重置，尝试更换 CDN 或通过 --proxy 使用代理")
+}
+
+// requestHint 根据请求的错误给出建议，TLS 握手失败时沿用握手的建议
+func requestHint(err error, tlsRes CheckResult) string {
+	var netErr net.Error
+	switch {
+	case tlsRes.Failed():
+		return tlsRes.Hint
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return T("请求超时，检查网络或代理，或更换 CDN")
+	}
+	return T("无法连接 CDN，检查 --cdn 和网络，或通过 --proxy 使用代理")
+}
+
+// statusHint 根据状态码给出建议
+func statusHint(err error) string {
+	var httpErr *HTTPError
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return T("示例页面不存在，检查车牌号、--url-template 和 --ext 是否正确")
+	case errors.Is(err, ErrUnauthorized):
+		return T("访问被拒绝，执行 pickit login 登录，或更换 CDN 和代理")
+	case errors.Is(err, ErrRateLimited):
+		return T("请求过于频繁，稍后再试或降低并发数")
+	case errors.As(err, &httpErr) && httpErr.StatusCode >= http.StatusInternalServerError:
+		return T("CDN 服务端出错，稍后再试或更换 CDN")
+	}
+	return T("检查 --cdn 和 --url-template 是否正确")
+}
+
+// hostPort 返回地址的主机和端口，没有端口时按协议补全
+func hostPort(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	port := "80"
+	switch u.Scheme {
+	case "https":
+		port = "443"
+	case "socks5", "socks5h":
+		port = "1080"
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// existingParent 返回 dir 或最近的已存在的上级目录
+func existingParent(dir string) string {
+	dir = filepath.Clean(dir)
+	for {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
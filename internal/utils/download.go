@@ -1,7 +1,8 @@
 package utils
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/url"
@@ -11,13 +12,18 @@ import (
 )
 
 type DownloadTask struct {
-	Url  string
-	Dist string
+	Url       string
+	Dist      string
+	Fallbacks []DownloadTask // 404 时依次尝试的备选地址（如其他扩展名）
 }
 
 type BatchDownloadResult struct {
-	Url string
-	Err error
+	Url      string // 实际下载成功的地址，失败时为原始地址
+	Dist     string // 实际保存的路径，失败时为原始路径
+	Err      error
+	Duration time.Duration // 下载耗时，包括重试和备选地址
+	Retries  int           // 重试次数，不包括尝试备选地址
+	Skipped  bool          // 清单中已下载完成，没有重新下载
 }
 
 // NewHTTPClient 创建带代理和超时的HTTP客户端
@@ -40,50 +46,87 @@ func NewHTTPClient(proxy string, timeout time.Duration) *http.Client {
 	// 创建带超时的客户端
 	Logger.Debug("创建HTTP客户端",
 		Float64("timeout_seconds", timeout.Seconds()))
-	return &http.Client{
+	client := &http.Client{
 		Transport: transport,
 		Timeout:   timeout,
 	}
+
+	return client
+}
+
+// Downloader 图片下载器，所有请求共用一个 HTTP 客户端
+type Downloader struct {
+	Client     *http.Client // HTTP 客户端，为 nil 时按 Proxy 创建，超时 15 秒
+	Proxy      string       // 魔法，Client 不为 nil 时忽略
+	MaxRetries int          // 失败后的最大重试次数
+
+	once   sync.Once
+	client *http.Client
+}
+
+// httpClient 返回下载使用的 HTTP 客户端
+func (d *Downloader) httpClient() *http.Client {
+	d.once.Do(func() {
+		d.client = d.Client
+		if d.client == nil {
+			d.client = NewHTTPClient(d.Proxy, 15*time.Second)
+		}
+	})
+	return d.client
 }
 
-// Download 下载函数
-func Download(url, dist, proxy string) (err error) {
-	Logger.Info("开始下载文件",
+// Download 下载单个文件，ctx 取消时中断下载
+func (d *Downloader) Download(ctx context.Context, url, dist string) error {
+	return d.download(ctx, url, dist, nil)
+}
+
+// download 下载单个文件，每次写入后调用 progress（可以为 nil），size 未知时为 -1
+func (d *Downloader) download(ctx context.Context, url, dist string, progress func(written, size int64)) (err error) {
+	logger := LoggerFrom(ctx)
+	logger.Info("开始下载文件",
 		Str("url", url),
-		Str("dist", dist),
-		Str("proxy", proxy))
+		Str("dist", dist))
 
 	// 创建HTTP请求
-	client := NewHTTPClient(proxy, 15*time.Second)
-	resp, err := client.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		err = Errorf("创建HTTP请求失败: %w (url=%s)", err, url)
+		logger.Error("下载失败", Err(err))
+		return err
+	}
+	resp, err := d.httpClient().Do(req)
 	if err != nil {
-		err = fmt.Errorf("HTTP请求失败: %w (url=%s)", err, url)
-		Logger.Error("下载失败", Err(err))
+		err = Errorf("HTTP请求失败: %w (url=%s)", err, url)
+		logger.Error("下载失败", Err(err))
 		return err
 	}
 	defer resp.Body.Close()
 
 	// 检查状态码
 	if resp.StatusCode != http.StatusOK {
-		err = fmt.Errorf("无效状态码: %d", resp.StatusCode)
-		Logger.Error("下载失败",
-			Err(err),
-			Int("status_code", resp.StatusCode))
+		err = newHTTPError(url, resp)
+		if errors.Is(err, ErrNotFound) {
+			logger.Warn("下载失败", Err(err))
+		} else {
+			logger.Error("下载失败",
+				Err(err),
+				Int("status_code", resp.StatusCode))
+		}
 		return err
 	}
 
 	// 创建目标文件
 	out, err := os.Create(dist)
 	if err != nil {
-		err = fmt.Errorf("文件创建失败: %w", err)
-		Logger.Error("下载失败", Err(err))
+		err = Errorf("文件创建失败: %w", err)
+		logger.Error("下载失败", Err(err))
 		return err
 	}
 
 	// 错误时删除文件
 	defer func() {
 		if err != nil {
-			Logger.Warn("删除不完整文件",
+			logger.Warn("删除不完整文件",
 				Str("file", dist),
 				Err(err))
 			os.Remove(dist) // 删除空白或不完整文件
@@ -93,155 +136,239 @@ func Download(url, dist, proxy string) (err error) {
 	defer out.Close()
 
 	// 复制数据
-	Logger.Debug("开始复制文件内容",
+	logger.Debug("开始复制文件内容",
 		Str("url", url),
 		Str("dist", dist))
-	if _, err := io.Copy(out, resp.Body); err != nil {
-		err = fmt.Errorf("文件复制失败: %w", err)
-		Logger.Error("下载失败", Err(err))
+	var body io.Reader = resp.Body
+	if progress != nil {
+		body = io.TeeReader(resp.Body, &progressWriter{size: resp.ContentLength, report: progress})
+	}
+	if _, err = io.Copy(out, body); err != nil {
+		err = Errorf("文件复制失败: %w", err)
+		logger.Error("下载失败", Err(err))
 		return err
 	}
 
-	Logger.Info("文件下载成功",
+	logger.Info("文件下载成功",
 		Str("url", url),
 		Str("dist", dist))
 	return nil
 }
 
-// DownloadWithRetry 带有重试机制的下载函数
-func DownloadWithRetry(url, dist, proxy string, maxRetries int) error {
-	Logger.Info("开始带重试的下载",
+// DownloadWithRetry 带有重试机制的下载，ctx 取消时停止重试
+func (d *Downloader) DownloadWithRetry(ctx context.Context, url, dist string) error {
+	_, err := d.downloadWithRetry(ctx, url, dist, nil)
+	return err
+}
+
+// downloadWithRetry 带有重试机制的下载，返回重试次数
+func (d *Downloader) downloadWithRetry(ctx context.Context, url, dist string, progress func(written, size int64)) (retries int, err error) {
+	logger := LoggerFrom(ctx)
+	logger.Info("开始带重试的下载",
 		Str("url", url),
 		Str("dist", dist),
-		Int("max_retries", maxRetries))
+		Int("max_retries", d.MaxRetries))
 
-	var err error
 	retryDelay := 1 * time.Second // 初始重试延迟
-	for attempt := 0; attempt <= maxRetries; attempt++ {
+	for attempt := 0; attempt <= d.MaxRetries; attempt++ {
 		if attempt > 0 {
-			// 说明第一次下载失败了，需要等待一段时间后重试
-			Logger.Warn("准备重试下载",
+			// 说明第一次下载失败了，需要等待一段时间后重试，被限流时按服务端要求的时间等待
+			delay := retryDelay
+			var httpErr *HTTPError
+			if errors.As(err, &httpErr) && httpErr.RetryAfter > delay {
+				delay = httpErr.RetryAfter
+			}
+			logger.Warn("准备重试下载",
 				Str("url", url),
 				Int("attempt", attempt),
-				Int("max_retries", maxRetries),
-				Float64("delay_seconds", retryDelay.Seconds()))
-			time.Sleep(retryDelay)
+				Int("max_retries", d.MaxRetries),
+				Float64("delay_seconds", delay.Seconds()),
+				Str("kind", ErrorKind(err)))
+			select {
+			case <-ctx.Done():
+				return retries, ctx.Err()
+			case <-time.After(delay):
+			}
 			retryDelay *= 2 // 每次重试延迟加倍
+			retries = attempt
 		}
 
 		// 执行下载
-		err = Download(url, dist, proxy)
+		err = d.download(ctx, url, dist, progress)
 		if err == nil {
 			// 下载成功
-			Logger.Info("重试下载成功",
+			logger.Info("重试下载成功",
 				Str("url", url),
 				Str("dist", dist),
 				Int("attempts", attempt+1))
-			return nil
+			return retries, nil
 		}
 
 		// 记录错误
-		Logger.Warn("下载尝试失败",
+		logger.Warn("下载尝试失败",
 			Str("url", url),
 			Int("attempt", attempt+1),
-			Int("max_retries", maxRetries),
+			Int("max_retries", d.MaxRetries),
 			Err(err))
+
+		// 已取消、资源不存在或需要登录时重试没有意义
+		if ctx.Err() != nil {
+			return retries, ctx.Err()
+		}
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrSessionExpired) {
+			return retries, err
+		}
 	}
 
 	// 所有重试失败后返回错误
-	err = fmt.Errorf("下载失败，已重试 %d 次: %w", maxRetries, err)
-	Logger.Error("所有重试均失败",
+	err = Errorf("下载失败，已重试 %d 次: %w", d.MaxRetries, err)
+	logger.Error("所有重试均失败",
 		Str("url", url),
-		Int("attempts", maxRetries),
+		Int("attempts", d.MaxRetries),
 		Err(err))
-	return err
+	return retries, err
+}
+
+// DownloadWithFallback 下载任务，404 时依次尝试备选地址
+func (d *Downloader) DownloadWithFallback(ctx context.Context, task DownloadTask) BatchDownloadResult {
+	return d.downloadWithFallback(ctx, task, nil)
 }
 
-// BatchDownload 多线程下载
-func BatchDownload(tasks []DownloadTask, proxy string, workers int, maxRetries int) []BatchDownloadResult {
+// downloadWithFallback 下载任务，progress（可以为 nil）的 candidate 为正在下载的地址
+func (d *Downloader) downloadWithFallback(ctx context.Context, task DownloadTask, progress func(candidate DownloadTask, written, size int64)) BatchDownloadResult {
+	logger := LoggerFrom(ctx)
+	candidates := append([]DownloadTask{task}, task.Fallbacks...)
+
+	var err error
+	total := 0
+	for i, candidate := range candidates {
+		if i > 0 {
+			logger.Info("尝试备选地址",
+				Str("url", candidate.Url),
+				Str("original", task.Url))
+		}
+
+		var candidateProgress func(written, size int64)
+		if progress != nil {
+			candidateProgress = func(written, size int64) { progress(candidate, written, size) }
+		}
+		retries, candidateErr := d.downloadWithRetry(ctx, candidate.Url, candidate.Dist, candidateProgress)
+		total += retries
+		err = candidateErr
+		if err == nil {
+			if i > 0 {
+				logger.Info("备选地址下载成功",
+					Str("url", candidate.Url),
+					Str("dist", candidate.Dist),
+					Str("original", task.Url))
+			}
+			return BatchDownloadResult{Url: candidate.Url, Dist: candidate.Dist, Retries: total}
+		}
+		if !errors.Is(err, ErrNotFound) {
+			break
+		}
+	}
+
+	return BatchDownloadResult{Url: task.Url, Dist: task.Dist, Err: err, Retries: total}
+}
+
+// BatchDownload 多线程下载，结果按任务顺序返回。处理过程中向 events（可以为 nil）发送 download 阶段的事件，
+// 单项事件的 Index 为任务下标。ctx 取消后尚未开始的任务不再下载，其结果的错误为 ctx.Err()
+func (d *Downloader) BatchDownload(ctx context.Context, tasks []DownloadTask, workers int, events EventHandler) []BatchDownloadResult {
+	logger := LoggerFrom(ctx)
+	batchStart := time.Now()
+	emit(events, Event{Type: EventJobStarted, Stage: StageDownload, Index: -1, Total: len(tasks)})
+
 	// 处理空任务列表
 	if len(tasks) == 0 {
-		Logger.Warn("批量下载接收到空任务列表")
+		logger.Warn("批量下载接收到空任务列表")
+		emit(events, Event{Type: EventJobFinished, Stage: StageDownload, Index: -1})
 		return []BatchDownloadResult{}
 	}
 
-	Logger.Info("开始批量下载任务",
+	logger.Info("开始批量下载任务",
 		Int("total_tasks", len(tasks)),
 		Int("workers", workers),
-		Int("max_retries", maxRetries))
+		Int("max_retries", d.MaxRetries))
 
 	// 限制worker数量不超过任务数
 	if workers > len(tasks) {
 		workers = len(tasks)
-		Logger.Debug("调整worker数量",
+		logger.Debug("调整worker数量",
 			Int("new_workers", workers))
 	}
+	if workers <= 0 {
+		workers = 1
+	}
 
 	var wg sync.WaitGroup
-	taskCh := make(chan DownloadTask, len(tasks))
-	resultCh := make(chan BatchDownloadResult, len(tasks))
+	taskCh := make(chan int, len(tasks))
 	results := make([]BatchDownloadResult, len(tasks))
 
-	// 准备任务通道
-	for _, task := range tasks {
-		taskCh <- task
+	// 准备任务通道（传递任务下标）
+	for i := range tasks {
+		taskCh <- i
 	}
 	close(taskCh)
 
 	// 启动工作协程池
-	Logger.Debug("启动下载工作协程",
+	logger.Debug("启动下载工作协程",
 		Int("worker_count", workers))
 	for i := 0; i < workers; i++ {
 		wg.Add(1)
 		go func(workerID int) {
 			defer wg.Done()
-			Logger.Debug("工作协程启动",
+			logger.Debug("工作协程启动",
 				Int("worker_id", workerID))
-			for task := range taskCh {
-				Logger.Debug("工作协程处理任务",
-					Int("worker_id", workerID),
-					Str("url", task.Url),
-					Str("dist", task.Dist))
-
-				err := DownloadWithRetry(task.Url, task.Dist, proxy, maxRetries)
-				resultCh <- BatchDownloadResult{
-					Url: task.Url,
-					Err: err,
+			for idx := range taskCh {
+				task := tasks[idx]
+				if err := ctx.Err(); err != nil {
+					results[idx] = BatchDownloadResult{Url: task.Url, Dist: task.Dist, Err: err}
+				} else {
+					logger.Debug("工作协程处理任务",
+						Int("worker_id", workerID),
+						Str("url", task.Url),
+						Str("dist", task.Dist))
+
+					start := time.Now()
+					emit(events, Event{Type: EventItemStarted, Stage: StageDownload, Index: idx, Total: len(tasks), Input: task.Url, Output: task.Dist})
+					var progress func(candidate DownloadTask, written, size int64)
+					if events != nil {
+						progress = func(candidate DownloadTask, written, size int64) {
+							events.HandleEvent(Event{Type: EventItemProgress, Stage: StageDownload, Index: idx, Total: len(tasks),
+								Input: candidate.Url, Output: candidate.Dist, Bytes: written, Size: size})
+						}
+					}
+					results[idx] = d.downloadWithFallback(ctx, task, progress)
+					results[idx].Duration = time.Since(start)
 				}
+				emit(events, resultEvent(StageDownload, idx, len(tasks), results[idx].Url, results[idx].Dist, results[idx].Err, results[idx].Retries, results[idx].Duration))
 			}
-			Logger.Debug("工作协程退出",
+			logger.Debug("工作协程退出",
 				Int("worker_id", workerID))
 		}(i)
 	}
 
-	// 收集结果协程
-	go func() {
-		wg.Wait()
-		close(resultCh)
-		Logger.Debug("所有工作协程已完成")
-	}()
+	wg.Wait()
+	logger.Debug("所有工作协程已完成")
 
-	// 按任务顺序重组结果（通过URL匹配）
+	// 结果已按任务顺序写入
 	successCount := 0
 	failureCount := 0
-	for res := range resultCh {
-		for i, task := range tasks {
-			if task.Url == res.Url {
-				results[i] = res
-				if res.Err == nil {
-					successCount++
-				} else {
-					failureCount++
-				}
-				break
-			}
+	for _, res := range results {
+		if res.Err == nil {
+			successCount++
+		} else {
+			failureCount++
 		}
 	}
 
-	Logger.Info("批量下载任务完成",
+	logger.Info("批量下载任务完成",
 		Int("total_tasks", len(tasks)),
 		Int("success_count", successCount),
 		Int("failure_count", failureCount))
+	emit(events, Event{Type: EventJobFinished, Stage: StageDownload, Index: -1, Total: len(tasks),
+		Succeeded: successCount, Failed: failureCount, Duration: time.Since(batchStart)})
 
 	return results
 }
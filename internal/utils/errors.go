@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// 错误类别，可以用 errors.Is 判断。具体的错误会带上地址、文件等上下文
+var (
+	ErrNotFound          = NewError("资源不存在")      // 404，不会重试
+	ErrRateLimited       = NewError("请求过于频繁")     // 429，按 Retry-After 延迟后重试
+	ErrCorruptImage      = NewError("图片损坏或格式不支持") // 无法解码图片
+	ErrUnsupportedLayout = NewError("不支持的目录结构")   // 如还原时输入目录包含子目录
+	ErrSchemeMismatch    = NewError("切割方案与图片不匹配") // 切割刀数超出图片高度等
+)
+
+// 错误类别的名称，用于 JSON 报告和任务文件
+const (
+	KindNotFound          = "not_found"
+	KindRateLimited       = "rate_limited"
+	KindUnauthorized      = "unauthorized"
+	KindSessionExpired    = "session_expired"
+	KindCorruptImage      = "corrupt_image"
+	KindUnsupportedLayout = "unsupported_layout"
+	KindSchemeMismatch    = "scheme_mismatch"
+	KindCancelled         = "cancelled"
+)
+
+// errorKinds 错误类别与名称的对应关系，按判断顺序排列
+var errorKinds = []struct {
+	kind string
+	err  error
+}{
+	{KindNotFound, ErrNotFound},
+	{KindRateLimited, ErrRateLimited},
+	{KindSessionExpired, ErrSessionExpired},
+	{KindUnauthorized, ErrUnauthorized},
+	{KindCorruptImage, ErrCorruptImage},
+	{KindUnsupportedLayout, ErrUnsupportedLayout},
+	{KindSchemeMismatch, ErrSchemeMismatch},
+	{KindCancelled, context.Canceled},
+	{KindCancelled, context.DeadlineExceeded},
+}
+
+// ErrorKind 返回错误类别的名称，不属于任何类别时返回空字符串
+func ErrorKind(err error) string {
+	if err == nil {
+		return ""
+	}
+	for _, k := range errorKinds {
+		if errors.Is(err, k.err) {
+			return k.kind
+		}
+	}
+	return ""
+}
+
+// KindError 还原保存为文本的错误，kind 为 ErrorKind 的返回值，还原后仍然可以用 errors.Is 判断类别
+func KindError(kind, msg string) error {
+	if msg == "" {
+		return nil
+	}
+	for _, k := range errorKinds {
+		if k.kind == kind {
+			return &kindError{msg: msg, kind: k.err}
+		}
+	}
+	return errors.New(msg)
+}
+
+type kindError struct {
+	msg  string
+	kind error
+}
+
+func (e *kindError) Error() string { return e.msg }
+func (e *kindError) Unwrap() error { return e.kind }
+
+// HTTPError 服务端返回了非 200 的状态码，可以用 errors.As 获取状态码
+type HTTPError struct {
+	Url        string
+	StatusCode int
+	RetryAfter time.Duration // 429 时服务端要求的等待时间，没有时为 0
+}
+
+// newHTTPError 根据响应创建错误
+func newHTTPError(url string, resp *http.Response) *HTTPError {
+	e := &HTTPError{Url: url, StatusCode: resp.StatusCode}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		e.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return e
+}
+
+func (e *HTTPError) Error() string {
+	if kind := e.Unwrap(); kind != nil {
+		return fmt.Sprintf(T("%v: 状态码 %d (url=%s)"), kind, e.StatusCode, e.Url)
+	}
+	return fmt.Sprintf(T("无效状态码: %d (url=%s)"), e.StatusCode, e.Url)
+}
+
+// Unwrap 返回状态码对应的错误类别
+func (e *HTTPError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+// ImageError 单张图片处理失败，可以用 errors.As 获取图片路径
+type ImageError struct {
+	Path string
+	Err  error
+}
+
+func (e *ImageError) Error() string {
+	return fmt.Sprintf(T("处理图片 %s 失败: %v"), e.Path, e.Err)
+}
+
+func (e *ImageError) Unwrap() error { return e.Err }
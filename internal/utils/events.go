@@ -0,0 +1,80 @@
+package utils
+
+import "time"
+
+// 事件类型
+const (
+	EventJobStarted    = "job_started"    // 一批任务开始处理
+	EventItemStarted   = "item_started"   // 单项开始处理
+	EventItemProgress  = "item_progress"  // 单项已传输的字节数发生变化，只有下载会产生
+	EventItemSucceeded = "item_succeeded" // 单项处理成功
+	EventItemFailed    = "item_failed"    // 单项处理失败
+	EventJobFinished   = "job_finished"   // 一批任务处理完成
+)
+
+// 产生事件的阶段
+const (
+	StageDownload = "download" // 批量下载，单项为一页
+	StageRestore  = "restore"  // 批量还原，单项为一张图片
+	StagePdf      = "pdf"      // 合成 PDF，单项为添加到 PDF 中的一张图片
+)
+
+// Event 进度和生命周期事件
+type Event struct {
+	Type     string
+	Stage    string
+	Index    int           // 单项在任务列表中的下标，任务级事件为 -1
+	Total    int           // 任务总数
+	Input    string        // 下载地址或源图片路径
+	Output   string        // 保存路径，下载使用了备选地址时为实际保存的路径
+	Bytes    int64         // 已传输的字节数
+	Size     int64         // 文件大小，未知时为 -1
+	Retries  int           // 已重试的次数
+	Err      error         // 失败原因，可以用 errors.Is 判断类别
+	Duration time.Duration // 单项或整批任务的耗时
+
+	Succeeded int // 成功的项数，只在 job_finished 中有效
+	Failed    int // 失败的项数，只在 job_finished 中有效
+}
+
+// EventHandler 接收事件，批量处理时会在多个协程中同时调用，实现需要自行处理并发
+type EventHandler interface {
+	HandleEvent(e Event)
+}
+
+// EventFunc 把函数转换为 EventHandler
+type EventFunc func(e Event)
+
+func (f EventFunc) HandleEvent(e Event) {
+	f(e)
+}
+
+// emit 发送事件，h 为 nil 时忽略
+func emit(h EventHandler, e Event) {
+	if h != nil {
+		h.HandleEvent(e)
+	}
+}
+
+// progressWriter 统计写入的字节数并发送 item_progress 事件
+type progressWriter struct {
+	written int64
+	size    int64
+	report  func(written, size int64)
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.written += int64(len(p))
+	w.report(w.written, w.size)
+	return len(p), nil
+}
+
+// resultEvent 单项处理结果对应的 item_succeeded 或 item_failed 事件
+func resultEvent(stage string, idx, total int, input, output string, err error, retries int, duration time.Duration) Event {
+	e := Event{Type: EventItemSucceeded, Stage: stage, Index: idx, Total: total, Input: input, Output: output,
+		Retries: retries, Err: err, Duration: duration}
+	if err != nil {
+		e.Type = EventItemFailed
+	}
+	return e
+}
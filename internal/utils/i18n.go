@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"strings"
+)
+
+// 支持的界面语言
+const (
+	LangZh = "zh"
+	LangEn = "en"
+)
+
+// catalogs 各语言的消息目录，键为代码中的中文原文，中文目录为空表示直接使用原文
+var catalogs = map[string]map[string]string{
+	LangZh: nil,
+	LangEn: enMessages,
+}
+
+// locale 当前语言，需要在开始处理之前设置
+var locale = LangZh
+
+// SetLocale 设置命令帮助、错误和日志消息使用的语言，支持 zh、en，也接受 en_US.UTF-8 这种形式
+func SetLocale(lang string) error {
+	normalized := normalizeLang(lang)
+	if _, ok := catalogs[normalized]; !ok {
+		return Errorf("不支持的语言 %q，可选 zh、en", lang)
+	}
+	locale = normalized
+	return nil
+}
+
+// Locale 返回当前语言
+func Locale() string {
+	return locale
+}
+
+// DetectLocale 依次根据 LC_ALL、LC_MESSAGES、LANG 环境变量推断语言，无法识别时使用中文
+func DetectLocale() string {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		lang := normalizeLang(value)
+		if _, ok := catalogs[lang]; ok {
+			return lang
+		}
+		// 设置了但无法识别（如 C.UTF-8）时不再查看优先级更低的变量
+		break
+	}
+	return LangZh
+}
+
+// normalizeLang 去掉地区和编码，例如 en_US.UTF-8 -> en
+func normalizeLang(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	return lang
+}
+
+// T 返回消息在当前语言下的文本，目录中没有时返回原文
+func T(msg string) string {
+	if translated, ok := catalogs[locale][msg]; ok {
+		return translated
+	}
+	return msg
+}
+
+// Errorf 同 fmt.Errorf，格式串按当前语言翻译
+func Errorf(format string, args ...any) error {
+	return fmt.Errorf(T(format), args...)
+}
+
+// localizedError 错误文本在输出时按当前语言翻译，用于包级别的哨兵错误
+type localizedError struct {
+	msg string
+}
+
+// NewError 同 errors.New，错误文本在输出时按当前语言翻译
+func NewError(msg string) error {
+	return &localizedError{msg: msg}
+}
+
+func (e *localizedError) Error() string {
+	return T(e.msg)
+}
+
+// LocalizeLogger 返回按当前语言输出日志消息的日志器，字段名保持不变
+func LocalizeLogger(logger *zap.Logger) *zap.Logger {
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if _, ok := core.(localizeCore); ok {
+			return core
+		}
+		return localizeCore{core}
+	}))
+}
+
+// localizeCore 写入前按当前语言翻译日志消息
+type localizeCore struct {
+	zapcore.Core
+}
+
+func (c localizeCore) With(fields []zapcore.Field) zapcore.Core {
+	return localizeCore{c.Core.With(fields)}
+}
+
+// Check 只按级别粗略判断，内部 core 的级别过滤和采样在 Write 中通过 writeChecked 执行
+func (c localizeCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c localizeCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = T(entry.Message)
+	return writeChecked(c.Core, entry, fields)
+}
+
+// writeChecked 先经过 core 自己的 Check 再写入，Tee 中每个 core 的级别过滤和采样都保持有效。
+// 写入错误由 CheckedEntry 输出到 ErrorOutput
+func writeChecked(core zapcore.Core, entry zapcore.Entry, fields []zapcore.Field) error {
+	if checked := core.Check(entry, nil); checked != nil {
+		checked.Write(fields...)
+	}
+	return nil
+}
@@ -1,76 +1,105 @@
 package utils
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/disintegration/imaging"
 	_ "golang.org/x/image/webp"
 	"image"
+	"io/fs"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 )
 
 type DecodeAndSaveTask struct {
 	ImgSrcPath      string
 	DecodedSavePath string
+	Segments        *int // 外部提供的切割刀数，为 nil 时使用 GetNum 计算
 }
 
 type DecodeAndSaveResult struct {
-	ImgSrcPath string
-	err        error
+	ImgSrcPath      string
+	DecodedSavePath string
+	Segments        int // 使用的切割刀数
+	Err             error
+	Duration        time.Duration // 处理耗时
+	Skipped         bool          // 清单中已还原完成，没有重新还原
 }
 
-func DecodeAndSave(scrambleId, aid int, imgSrcPath, decodedSavePath string) error {
-	LogDebug("开始处理图片",
+func DecodeAndSave(ctx context.Context, scrambleId, aid int, imgSrcPath, decodedSavePath string) error {
+	LoggerFrom(ctx).Debug("开始处理图片",
 		Str("source", imgSrcPath),
 		Str("destination", decodedSavePath),
 		Int("scrambleId", scrambleId),
 		Int("aid", aid))
+	return DecodeSegmentsAndSave(ctx, imageSegments(ctx, scrambleId, aid, imgSrcPath), imgSrcPath, decodedSavePath)
+}
+
+// imageSegments 根据文件名计算图片的切割刀数
+func imageSegments(ctx context.Context, scrambleId, aid int, imgSrcPath string) int {
+	logger := LoggerFrom(ctx)
 	// 从路径中提取文件名（去除扩展名）
 	filename := filepath.Base(imgSrcPath)
 	filename = strings.TrimSuffix(filename, filepath.Ext(filename))
 	// 获取图片分割数
-	LogDebug("计算图片分割数量",
+	logger.Debug("计算图片分割数量",
 		Str("filename", filename),
 		Int("scrambleId", scrambleId),
 		Int("aid", aid))
 	num := GetNum(scrambleId, aid, filename)
-	LogInfo("图片分割计算结果",
+	logger.Info("图片分割计算结果",
 		Str("source", imgSrcPath),
 		Int("segments", num))
+	return num
+}
 
-	LogDebug("打开原始图像", Str("path", imgSrcPath))
+// DecodeSegmentsAndSave 按照给定的切割刀数还原图片
+func DecodeSegmentsAndSave(ctx context.Context, num int, imgSrcPath, decodedSavePath string) error {
+	logger := LoggerFrom(ctx)
+	logger.Debug("打开原始图像", Str("path", imgSrcPath))
 	// 打开原始图像
 	srcImg, err := imaging.Open(imgSrcPath)
 	if err != nil {
-		LogError("无法打开图像文件", Str("path", imgSrcPath), Err(err))
-		return fmt.Errorf("打开图片失败: %w", err)
+		logger.Error("无法打开图像文件", Str("path", imgSrcPath), Err(err))
+		var pathErr *fs.PathError
+		if !errors.As(err, &pathErr) {
+			// 文件存在但无法解码
+			err = fmt.Errorf("%w: %w", ErrCorruptImage, err)
+		}
+		return &ImageError{Path: imgSrcPath, Err: Errorf("打开图片失败: %w", err)}
 	}
 	// 无需解密，直接保存为JPEG
 	if num == 0 {
-		LogInfo("图片无需处理，直接保存",
+		logger.Info("图片无需处理，直接保存",
 			Str("source", imgSrcPath),
 			Str("destination", decodedSavePath))
-		return imaging.Save(srcImg, decodedSavePath)
+		return saveImage(srcImg, imgSrcPath, decodedSavePath)
 	}
 
 	// 获取图片尺寸
 	bounds := srcImg.Bounds()
 	width := bounds.Dx()
 	height := bounds.Dy()
-	LogDebug("图片尺寸信息",
+	logger.Debug("图片尺寸信息",
 		Int("width", width),
 		Int("height", height),
 		Int("segments", num))
+	if num < 0 || num > height {
+		return &ImageError{Path: imgSrcPath, Err: Errorf("%w: 切割 %d 刀，图片高度为 %d", ErrSchemeMismatch, num, height)}
+	}
+
 	// 计算每段高度
 	segmentHeight := height / num
 	remainder := height % num
-	LogDebug("计算分段高度",
+	logger.Debug("计算分段高度",
 		Int("segmentHeight", segmentHeight),
 		Int("remainder", remainder))
 	// 创建一个新的透明背景画布
 	dstImg := imaging.New(width, height, image.Transparent)
-	LogDebug("创建透明背景画布",
+	logger.Debug("创建透明背景画布",
 		Int("width", width),
 		Int("height", height))
 	// 当前粘贴位置Y坐标
@@ -90,11 +119,11 @@ func DecodeAndSave(scrambleId, aid int, imgSrcPath, decodedSavePath string) erro
 		// 如果源图坐标过低，限制在范围内
 		if srcY < 0 {
 			srcY = 0
-			LogDebug("调整源图Y坐标为0",
+			logger.Debug("调整源图Y坐标为0",
 				Int("segment", i+1),
 				Int("originalY", height-(segmentHeight*(i+1)+remainder)))
 		}
-		LogDebug("处理图像分段",
+		logger.Debug("处理图像分段",
 			Int("segment", i+1),
 			Int("height", currentSegmentHeight),
 			Int("sourceY", srcY))
@@ -110,103 +139,129 @@ func DecodeAndSave(scrambleId, aid int, imgSrcPath, decodedSavePath string) erro
 			srcSegment,
 			image.Pt(0, dstY),
 		)
-		LogDebug("更新粘贴位置",
+		logger.Debug("更新粘贴位置",
 			Int("segment", i+1),
 			Int("currentY", dstY))
 		// 更新粘贴位置
 		dstY += currentSegmentHeight
 	}
-	LogInfo("保存最终结果图像",
+	logger.Info("保存最终结果图像",
 		Str("path", decodedSavePath))
 	// 保存结果图像为JPEG
-	return imaging.Save(dstImg, decodedSavePath)
+	return saveImage(dstImg, imgSrcPath, decodedSavePath)
 }
 
-func BatchDecodeAndSave(scrambleId, aid int, items []DecodeAndSaveTask, workers int) []DecodeAndSaveResult {
-	LogInfo("开始批量处理图片",
+// saveImage 保存还原后的图片，失败时返回 ImageError
+func saveImage(img image.Image, imgSrcPath, decodedSavePath string) error {
+	if err := imaging.Save(img, decodedSavePath); err != nil {
+		return &ImageError{Path: imgSrcPath, Err: Errorf("保存图片失败: %w", err)}
+	}
+	return nil
+}
+
+// BatchDecodeAndSave 多线程还原图片，结果按任务顺序返回。处理过程中向 events（可以为 nil）发送 restore 阶段的事件，
+// 单项事件的 Index 为任务下标。ctx 取消后尚未开始的图片不再处理，其结果的错误为 ctx.Err()
+func BatchDecodeAndSave(ctx context.Context, scrambleId, aid int, items []DecodeAndSaveTask, workers int, events EventHandler) []DecodeAndSaveResult {
+	logger := LoggerFrom(ctx)
+	batchStart := time.Now()
+	emit(events, Event{Type: EventJobStarted, Stage: StageRestore, Index: -1, Total: len(items)})
+	logger.Info("开始批量处理图片",
 		Int("total", len(items)),
 		Int("workers", workers),
 		Int("scrambleId", scrambleId),
 		Int("aid", aid))
 
 	if len(items) == 0 {
-		LogWarn("没有需要处理的图片，批量处理终止")
+		logger.Warn("没有需要处理的图片，批量处理终止")
+		emit(events, Event{Type: EventJobFinished, Stage: StageRestore, Index: -1})
 		return nil
 	}
 	if workers <= 0 {
 		workers = 1
-		LogWarn("无效的工作线程数量，使用默认值",
+		logger.Warn("无效的工作线程数量，使用默认值",
 			Int("provided", workers),
 			Int("actual", 1))
 	}
 
-	tasks := make(chan DecodeAndSaveTask, len(items))
-	results := make(chan DecodeAndSaveResult, len(items))
+	tasks := make(chan int, len(items))
+	res := make([]DecodeAndSaveResult, len(items))
 
 	var wg sync.WaitGroup
 
-	LogDebug("启动工作线程", Int("count", workers))
+	logger.Debug("启动工作线程", Int("count", workers))
 	for i := 0; i < workers; i++ {
 		wg.Add(1)
 		go func(workerID int) {
 			defer wg.Done()
-			LogDebug("工作线程开始处理任务", Int("worker", workerID))
-			for task := range tasks {
-				LogDebug("工作线程处理新任务",
+			logger.Debug("工作线程开始处理任务", Int("worker", workerID))
+			for idx := range tasks {
+				task := items[idx]
+				start := time.Now()
+				logger.Debug("工作线程处理新任务",
 					Int("worker", workerID),
 					Str("source", task.ImgSrcPath))
 
-				err := DecodeAndSave(scrambleId, aid, task.ImgSrcPath, task.DecodedSavePath)
+				num := 0
+				err := ctx.Err()
+				if err == nil {
+					emit(events, Event{Type: EventItemStarted, Stage: StageRestore, Index: idx, Total: len(items),
+						Input: task.ImgSrcPath, Output: task.DecodedSavePath})
+					if task.Segments != nil {
+						num = *task.Segments
+					} else {
+						num = imageSegments(ctx, scrambleId, aid, task.ImgSrcPath)
+					}
+					err = DecodeSegmentsAndSave(ctx, num, task.ImgSrcPath, task.DecodedSavePath)
+				}
 
 				if err != nil {
-					LogError("图片处理失败",
+					logger.Error("图片处理失败",
 						Int("worker", workerID),
 						Str("source", task.ImgSrcPath),
 						Err(err))
 				} else {
-					LogDebug("图片处理成功",
+					logger.Debug("图片处理成功",
 						Int("worker", workerID),
 						Str("source", task.ImgSrcPath))
 				}
 
-				results <- DecodeAndSaveResult{
-					ImgSrcPath: task.ImgSrcPath,
-					err:        err,
+				res[idx] = DecodeAndSaveResult{
+					ImgSrcPath:      task.ImgSrcPath,
+					DecodedSavePath: task.DecodedSavePath,
+					Segments:        num,
+					Err:             err,
+					Duration:        time.Since(start),
 				}
+				emit(events, resultEvent(StageRestore, idx, len(items), task.ImgSrcPath, task.DecodedSavePath, err, 0, res[idx].Duration))
 			}
-			LogDebug("工作线程结束", Int("worker", workerID))
+			logger.Debug("工作线程结束", Int("worker", workerID))
 		}(i + 1)
 	}
 
-	LogDebug("分发任务到工作线程")
-	for _, item := range items {
-		tasks <- item
+	logger.Debug("分发任务到工作线程")
+	for i := range items {
+		tasks <- i
 	}
 	close(tasks)
-	LogInfo("所有任务已分发到工作队列")
+	logger.Info("所有任务已分发到工作队列")
 
-	LogDebug("等待所有工作线程完成")
+	logger.Debug("等待所有工作线程完成")
 	wg.Wait()
-	LogInfo("所有工作线程已完成处理")
-
-	close(results)
-
-	res := make([]DecodeAndSaveResult, 0, len(items))
-	for result := range results {
-		res = append(res, result)
-	}
+	logger.Info("所有工作线程已完成处理")
 
 	successCount := 0
 	for _, r := range res {
-		if r.err == nil {
+		if r.Err == nil {
 			successCount++
 		}
 	}
 
-	LogInfo("批量处理完成",
+	logger.Info("批量处理完成",
 		Int("total", len(res)),
 		Int("success", successCount),
 		Int("failed", len(res)-successCount))
+	emit(events, Event{Type: EventJobFinished, Stage: StageRestore, Index: -1, Total: len(items),
+		Succeeded: successCount, Failed: len(res) - successCount, Duration: time.Since(batchStart)})
 
 	return res
 }
@@ -0,0 +1,272 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 任务状态
+const (
+	JobPending   = "pending"   // 等待执行
+	JobRunning   = "running"   // 执行中（进程被杀死后也会停留在该状态，可以直接恢复）
+	JobDone      = "done"      // 已完成
+	JobFailed    = "failed"    // 部分或全部页面失败
+	JobCancelled = "cancelled" // 已取消
+)
+
+// 页面状态，按处理顺序排列
+const (
+	PagePending    = "pending"    // 等待下载
+	PageDownloaded = "downloaded" // 已下载
+	PageRestored   = "restored"   // 已还原
+	PageExported   = "exported"   // 已合成 PDF
+)
+
+// JobPage 任务中的单个页面
+type JobPage struct {
+	Chapter int    `json:"chapter"`         // 章节序号
+	Aid     int    `json:"aid"`             // 章节编号，还原时作为车牌号使用
+	Url     string `json:"url"`             // 下载地址
+	Raw     string `json:"raw"`             // 原图路径，下载后为实际保存的路径
+	Image   string `json:"image,omitempty"` // 还原后的图片路径
+	State   string `json:"state"`
+	Error   string `json:"error,omitempty"` // 最近一次失败的原因
+	Kind    string `json:"kind,omitempty"`  // 失败原因的类别，见 ErrorKind
+}
+
+// SetError 记录页面失败的原因，err 为 nil 时清除
+func (p *JobPage) SetError(err error) {
+	p.Error, p.Kind = errorText(err), ErrorKind(err)
+}
+
+// Err 页面失败的原因，可以用 errors.Is 判断类别，没有失败时返回 nil
+func (p *JobPage) Err() error {
+	return KindError(p.Kind, p.Error)
+}
+
+// Job 单个本子的处理任务
+type Job struct {
+	Id        string    `json:"id"`
+	Input     string    `json:"input"` // 用户填写的车牌号
+	Aid       int       `json:"aid"`
+	Cdn       string    `json:"cdn"`
+	Count     int       `json:"count,omitempty"`
+	Chapters  string    `json:"chapters,omitempty"`
+	Output    string    `json:"output"`
+	Export    string    `json:"export"`
+	Password  string    `json:"password,omitempty"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"` // 导致整个任务失败的错误
+	Kind      string    `json:"kind,omitempty"`  // 错误的类别，见 ErrorKind
+	Pages     []JobPage `json:"pages"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SetError 记录导致整个任务失败的错误，err 为 nil 时清除
+func (j *Job) SetError(err error) {
+	j.Error, j.Kind = errorText(err), ErrorKind(err)
+}
+
+// Err 导致整个任务失败的错误，可以用 errors.Is 判断类别，没有时返回 nil
+func (j *Job) Err() error {
+	return KindError(j.Kind, j.Error)
+}
+
+// errorText 错误信息，err 为 nil 时返回空字符串
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// TargetState 任务完成时页面应达到的状态
+func (j *Job) TargetState() string {
+	switch j.Export {
+	case ExportImages:
+		return PageRestored
+	case ExportPdf:
+		return PageExported
+	default:
+		return PageDownloaded
+	}
+}
+
+// Progress 已完成的页数
+func (j *Job) Progress() int {
+	done := 0
+	for _, p := range j.Pages {
+		if pageStateReached(p.State, j.TargetState()) {
+			done++
+		}
+	}
+	return done
+}
+
+// FailedPages 失败且未完成的页数
+func (j *Job) FailedPages() int {
+	failed := 0
+	for _, p := range j.Pages {
+		if p.Error != "" && !pageStateReached(p.State, j.TargetState()) {
+			failed++
+		}
+	}
+	return failed
+}
+
+// pageStateReached 判断页面状态是否已达到目标状态
+func pageStateReached(state, target string) bool {
+	order := map[string]int{PagePending: 0, PageDownloaded: 1, PageRestored: 2, PageExported: 3}
+	return order[state] >= order[target]
+}
+
+// JobStore 任务存储，每个任务保存为目录中的一个 JSON 文件，写入时先写临时文件再重命名
+type JobStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// DefaultJobDir 默认的任务目录: <用户配置目录>/pickit/jobs
+func DefaultJobDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return filepath.Join(".", "jobs")
+	}
+	return filepath.Join(dir, "pickit", "jobs")
+}
+
+// OpenJobStore 打开任务目录，不存在时创建
+func OpenJobStore(dir string) (*JobStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, Errorf("创建任务目录失败: %w", err)
+	}
+	return &JobStore{dir: dir}, nil
+}
+
+// ReadJobStore 只读打开任务目录，目录不存在时不创建，用于演练模式
+func ReadJobStore(dir string) *JobStore {
+	return &JobStore{dir: dir}
+}
+
+// NewJob 创建任务并保存
+func (s *JobStore) NewJob(job *Job) error {
+	now := time.Now()
+	job.CreatedAt = now
+	job.Status = JobPending
+	if job.Pages == nil {
+		job.Pages = []JobPage{}
+	}
+
+	// 任务编号: 时间-车牌号，重复时追加序号
+	base := fmt.Sprintf("%s-%d", now.Format("20060102150405"), job.Aid)
+	job.Id = base
+	for i := 2; ; i++ {
+		if _, err := os.Stat(s.path(job.Id)); os.IsNotExist(err) {
+			break
+		}
+		job.Id = fmt.Sprintf("%s-%d", base, i)
+	}
+	return s.Save(job)
+}
+
+// Save 保存任务
+func (s *JobStore) Save(job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.save(job)
+}
+
+// Update 在锁内修改任务并保存，多个协程同时更新同一任务时使用
+func (s *JobStore) Update(job *Job, fn func()) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn()
+	return s.save(job)
+}
+
+// save 写入任务文件。任务中可能保存了 PDF 密码，文件权限为 0600
+func (s *JobStore) save(job *Job) error {
+	job.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return Errorf("序列化任务失败: %w", err)
+	}
+	if err := WriteFileAtomic(s.path(job.Id), data, 0600); err != nil {
+		return Errorf("保存任务 %s 失败: %w", job.Id, err)
+	}
+	return nil
+}
+
+// Get 读取任务
+func (s *JobStore) Get(id string) (*Job, error) {
+	data, err := os.ReadFile(s.path(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, Errorf("任务 %s 不存在", id)
+		}
+		return nil, Errorf("读取任务失败: %w", err)
+	}
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, Errorf("解析任务 %s 失败: %w", id, err)
+	}
+	return &job, nil
+}
+
+// List 按创建时间列出所有任务，无法解析的任务文件会被跳过
+func (s *JobStore) List() ([]*Job, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, Errorf("读取任务目录失败: %w", err)
+	}
+
+	jobs := make([]*Job, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		// 跳过写入中途退出留下的临时文件
+		if entry.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != ".json" {
+			continue
+		}
+		job, err := s.Get(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			LogWarn("跳过无法读取的任务", Str("file", name), Err(err))
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+	return jobs, nil
+}
+
+// FindUnfinished 查找相同车牌号和输出路径、尚未完成的任务，用于恢复中断的任务
+func (s *JobStore) FindUnfinished(input, output string) (*Job, error) {
+	jobs, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(jobs) - 1; i >= 0; i-- {
+		job := jobs[i]
+		if job.Input != input || job.Output != output {
+			continue
+		}
+		switch job.Status {
+		case JobPending, JobRunning, JobFailed:
+			return job, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *JobStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
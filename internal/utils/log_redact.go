@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const redacted = "***"
+
+var (
+	// redactUrlPattern 日志文本中的地址
+	redactUrlPattern = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s"'<>()]+`)
+	// redactAidPattern 日志文本中带前缀的车牌号，例如 JM350234
+	redactAidPattern = regexp.MustCompile(`(?i)\bjm\d+\b`)
+)
+
+// redactKeys 值为车牌号、标题或由它们组成的字段，整个值都会被隐藏
+var redactKeys = map[string]bool{
+	"aid":   true,
+	"id":    true,
+	"input": true,
+	"job":   true,
+	"title": true,
+}
+
+// redactPathKeys 值为文件路径的字段，隐藏其中的数字目录名
+var redactPathKeys = map[string]bool{
+	"path":        true,
+	"file":        true,
+	"dir":         true,
+	"dist":        true,
+	"source":      true,
+	"destination": true,
+	"output":      true,
+	"output_file": true,
+	"input_dir":   true,
+}
+
+// redactCore 在写入前隐藏日志中的地址和车牌号
+type redactCore struct {
+	zapcore.Core
+}
+
+func (c redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return redactCore{c.Core.With(redactFields(fields))}
+}
+
+// Check 同 localizeCore.Check
+func (c redactCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c redactCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = redactText(entry.Message)
+	return writeChecked(c.Core, entry, redactFields(fields))
+}
+
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		switch {
+		case redactKeys[f.Key]:
+			f = zap.String(f.Key, redacted)
+		case f.Type == zapcore.StringType:
+			f.String = redactText(f.String)
+			if redactPathKeys[f.Key] {
+				f.String = redactPath(f.String)
+			}
+		case f.Type == zapcore.ErrorType:
+			if err, ok := f.Interface.(error); ok && err != nil {
+				f = zap.String(f.Key, redactText(err.Error()))
+			}
+		}
+		out[i] = f
+	}
+	return out
+}
+
+// redactText 隐藏文本中的地址和带前缀的车牌号
+func redactText(text string) string {
+	if !strings.Contains(text, "://") && !strings.ContainsAny(text, "jJ") {
+		return text
+	}
+	text = redactUrlPattern.ReplaceAllString(text, redacted)
+	return redactAidPattern.ReplaceAllString(text, redacted)
+}
+
+// redactPath 隐藏路径中全是数字的目录名，这些目录一般以车牌号或章节号命名
+func redactPath(path string) string {
+	parts := strings.Split(filepath.ToSlash(path), "/")
+	for i, part := range parts[:len(parts)-1] {
+		if part != "" && strings.Trim(part, "0123456789") == "" {
+			parts[i] = redacted
+		}
+	}
+	return strings.Join(parts, "/")
+}
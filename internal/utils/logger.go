@@ -1,17 +1,63 @@
 package utils
 
 import (
+	"context"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"os"
 )
 
-// Logger 全局日志器
-var Logger *zap.Logger
+// Logger 全局日志器，未初始化时不输出任何日志
+var Logger = zap.NewNop()
 
-// InitLogger 显式初始化日志器
+type loggerKey struct{}
+
+// WithLogger 返回携带日志器的 context，下载、还原和合成 PDF 时优先使用 context 中的日志器
+func WithLogger(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// LoggerFrom 返回 context 中的日志器，没有时返回全局日志器
+func LoggerFrom(ctx context.Context) *zap.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*zap.Logger); ok && logger != nil {
+		return logger
+	}
+	return Logger
+}
+
+// 日志格式
+const (
+	LogFormatConsole = "console"
+	LogFormatJson    = "json"
+)
+
+// LogOptions 日志配置
+type LogOptions struct {
+	Level      string // 日志级别: debug、info、warn、error，默认 info
+	Format     string // 日志格式: console、json，默认 console
+	File       string // 日志文件，为空时输出到标准错误
+	MaxSize    int    // 日志文件超过该大小（MB）后轮转，默认 10
+	MaxBackups int    // 保留的历史日志文件数量，默认 3
+	Redact     bool   // 隐藏日志中的地址和车牌号
+}
+
+// logFile 当前打开的日志文件，重新配置或关闭日志器时关闭
+var logFile *rotatingFile
+
+// InitLogger 显式初始化日志器，使用默认配置输出到标准错误，标准输出留给 JSON 报告等数据
 func InitLogger() {
-	// 控制台输出编码器配置
+	_ = ConfigureLogger(LogOptions{})
+}
+
+// ConfigureLogger 按配置重新创建全局日志器，参数无效时保留原来的日志器并返回错误
+func ConfigureLogger(opts LogOptions) error {
+	level := zap.InfoLevel
+	if opts.Level != "" {
+		if err := level.UnmarshalText([]byte(opts.Level)); err != nil {
+			return Errorf("无效的日志级别 %q，可选 debug、info、warn、error", opts.Level)
+		}
+	}
+
 	encoderConfig := zapcore.EncoderConfig{
 		TimeKey:        "time",
 		LevelKey:       "level",
@@ -26,18 +72,42 @@ func InitLogger() {
 		EncodeDuration: zapcore.SecondsDurationEncoder,
 		EncodeCaller:   zapcore.ShortCallerEncoder, // 短文件路径
 	}
+	// 写入文件时不输出颜色控制符
+	if opts.File != "" {
+		encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+	}
 
-	// 使用控制台编码器
-	consoleEncoder := zapcore.NewConsoleEncoder(encoderConfig)
+	var encoder zapcore.Encoder
+	switch opts.Format {
+	case "", LogFormatConsole:
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	case LogFormatJson:
+		encoderConfig.EncodeLevel = zapcore.LowercaseLevelEncoder
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	default:
+		return Errorf("无效的日志格式 %q，可选 console、json", opts.Format)
+	}
 
-	// 创建核心 - 仅输出到控制台
-	core := zapcore.NewCore(
-		consoleEncoder,
-		zapcore.AddSync(os.Stdout), // 只输出到控制台
-		zap.InfoLevel,              // info 的级别
-	)
+	var out zapcore.WriteSyncer = zapcore.Lock(os.Stderr)
+	var file *rotatingFile
+	if opts.File != "" {
+		var err error
+		file, err = openRotatingFile(opts.File, opts.MaxSize, opts.MaxBackups)
+		if err != nil {
+			return Errorf("打开日志文件失败: %w", err)
+		}
+		out = file
+	}
 
-	// 创建日志器
+	var core zapcore.Core = zapcore.NewCore(encoder, out, level)
+	if opts.Redact {
+		core = redactCore{core}
+	}
+	core = localizeCore{core}
+
+	SyncLogger()
+	closeLogFile()
+	logFile = file
 	Logger = zap.New(core,
 		zap.AddCaller(),                   // 添加调用者信息
 		zap.AddCallerSkip(1),              // 跳过封装函数
@@ -46,6 +116,15 @@ func InitLogger() {
 
 	// 重定向标准log库输出到zap
 	zap.RedirectStdLog(Logger)
+	return nil
+}
+
+// closeLogFile 关闭当前的日志文件
+func closeLogFile() {
+	if logFile != nil {
+		_ = logFile.Close()
+		logFile = nil
+	}
 }
 
 // 快捷日志方法 =======================================
@@ -66,10 +145,6 @@ func LogError(msg string, fields ...zap.Field) {
 	Logger.Error(msg, fields...)
 }
 
-func LogFatal(msg string, fields ...zap.Field) {
-	Logger.Fatal(msg, fields...)
-}
-
 // 上下文字段快捷方法 =================================
 
 func Str(key, value string) zap.Field {
@@ -0,0 +1,236 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/disintegration/imaging"
+	"image"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ManifestName 清单文件名，位于命令的输出目录中。以 . 开头，读取图片目录时会被跳过
+const ManifestName = ".pickit-manifest.json"
+
+// manifestVersion 清单格式的版本
+const manifestVersion = 1
+
+// ManifestEntry 清单中的一个文件
+type ManifestEntry struct {
+	Stage    string `json:"stage"`              // 生成文件的阶段: download、restore、pdf
+	Source   string `json:"source"`             // 下载地址，或相对于清单目录的原图路径、PDF 的图片目录
+	Path     string `json:"path"`               // 相对于清单目录的输出文件
+	Size     int64  `json:"size"`               // 文件大小
+	Sha256   string `json:"sha256"`             // 文件内容的 SHA-256
+	Width    int    `json:"width,omitempty"`    // 图片宽度，不是图片时为 0
+	Height   int    `json:"height,omitempty"`   // 图片高度，不是图片时为 0
+	Segments *int   `json:"segments,omitempty"` // 还原时的切割刀数，只有 restore 阶段有
+}
+
+// Manifest 本子清单，记录输出目录中每个文件的来源、大小、哈希和尺寸，用于 verify 检查文件是否完整
+type Manifest struct {
+	Version   int             `json:"version"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Files     []ManifestEntry `json:"files"`
+
+	dir string
+}
+
+// LoadManifest 读取 dir 中的清单，不存在时返回空清单
+func LoadManifest(dir string) (*Manifest, error) {
+	m := &Manifest{Version: manifestVersion, dir: dir}
+	data, err := os.ReadFile(filepath.Join(dir, ManifestName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, Errorf("读取清单失败: %w", err)
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, Errorf("解析清单 %s 失败: %w", filepath.Join(dir, ManifestName), err)
+	}
+	return m, nil
+}
+
+// FindManifests 返回 dir 及其子目录中所有清单所在的目录
+func FindManifests(dir string) ([]string, error) {
+	dirs := make([]string, 0)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && d.Name() == ManifestName {
+			dirs = append(dirs, filepath.Dir(p))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, Errorf("查找清单失败: %w", err)
+	}
+	return dirs, nil
+}
+
+// Dir 清单所在的目录
+func (m *Manifest) Dir() string {
+	return m.dir
+}
+
+// Abs 返回清单中的路径对应的实际路径
+func (m *Manifest) Abs(p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(m.dir, filepath.FromSlash(p))
+}
+
+// Add 添加或替换输出路径相同的文件
+func (m *Manifest) Add(entries ...ManifestEntry) {
+	index := make(map[string]int, len(m.Files))
+	for i, e := range m.Files {
+		index[e.Path] = i
+	}
+	for _, e := range entries {
+		if i, ok := index[e.Path]; ok {
+			m.Files[i] = e
+			continue
+		}
+		index[e.Path] = len(m.Files)
+		m.Files = append(m.Files, e)
+	}
+}
+
+// Save 按输出路径排序后保存清单
+func (m *Manifest) Save() error {
+	sort.SliceStable(m.Files, func(i, j int) bool {
+		return m.Files[i].Path < m.Files[j].Path
+	})
+	m.Version, m.UpdatedAt = manifestVersion, time.Now()
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return Errorf("序列化清单失败: %w", err)
+	}
+	if err := WriteFileAtomic(filepath.Join(m.dir, ManifestName), data, 0644); err != nil {
+		return Errorf("保存清单失败: %w", err)
+	}
+	return nil
+}
+
+// NewEntry 读取文件并生成清单条目，file 和 source 保存为相对于清单目录的路径
+func (m *Manifest) NewEntry(stage, source, file string, segments *int) (ManifestEntry, error) {
+	entry := ManifestEntry{Stage: stage, Source: source, Path: m.rel(file), Segments: segments}
+	if stage != StageDownload {
+		entry.Source = m.rel(source)
+	}
+	info, err := FileDigest(file)
+	if err != nil {
+		return entry, err
+	}
+	entry.Size, entry.Sha256 = info.Size, info.Sha256
+	if stage != StagePdf {
+		entry.Width, entry.Height = info.Width, info.Height
+	}
+	return entry, nil
+}
+
+// Completed 判断 file 是否已在 stage 阶段由 source 生成，并且大小和哈希与清单一致。
+// 用于中断后再次执行时跳过已完成的页面，返回清单中的条目
+func (m *Manifest) Completed(stage, source, file string) (ManifestEntry, bool) {
+	p := m.rel(file)
+	if stage != StageDownload {
+		source = m.rel(source)
+	}
+	for _, entry := range m.Files {
+		if entry.Path != p {
+			continue
+		}
+		if entry.Stage != stage || entry.Source != source {
+			return entry, false
+		}
+		d, err := FileDigest(file)
+		return entry, err == nil && d.Size == entry.Size && d.Sha256 == entry.Sha256
+	}
+	return ManifestEntry{}, false
+}
+
+// rel 返回相对于清单目录的路径，整个目录移动后仍然有效。无法转换时（如 Windows 下不在同一个盘）返回绝对路径
+func (m *Manifest) rel(p string) string {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return p
+	}
+	dir, err := filepath.Abs(m.dir)
+	if err != nil {
+		return abs
+	}
+	if rel, err := filepath.Rel(dir, abs); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return abs
+}
+
+// Digest 文件的大小、哈希和图片尺寸
+type Digest struct {
+	Size   int64
+	Sha256 string
+	Width  int // 不是图片或无法识别时为 0
+	Height int
+}
+
+// FileDigest 计算文件的大小和 SHA-256，是图片时同时读取尺寸
+func FileDigest(file string) (Digest, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return Digest{}, Errorf("打开文件失败: %w", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return Digest{}, Errorf("读取文件失败: %w", err)
+	}
+	d := Digest{Size: size, Sha256: hex.EncodeToString(h.Sum(nil))}
+
+	if _, err := f.Seek(0, io.SeekStart); err == nil {
+		if cfg, _, err := image.DecodeConfig(f); err == nil {
+			d.Width, d.Height = cfg.Width, cfg.Height
+		}
+	}
+	return d, nil
+}
+
+// 校验发现的问题
+const (
+	IssueMissing  = "missing"       // 清单中的文件不存在
+	IssueGap      = "gap"           // 页码不连续，中间的页面不在清单中
+	IssueMismatch = "hash_mismatch" // 文件大小或哈希与清单不一致
+	IssueCorrupt  = "corrupt"       // 图片无法解码
+)
+
+// CheckEntry 检查清单中的文件是否存在、能否解码以及内容是否与清单一致，没有问题时返回空字符串
+func (m *Manifest) CheckEntry(entry ManifestEntry) (string, error) {
+	file := m.Abs(entry.Path)
+	d, err := FileDigest(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return IssueMissing, Errorf("%w: %s", ErrNotFound, file)
+	}
+	if err != nil {
+		return IssueMissing, err
+	}
+	if entry.Stage != StagePdf {
+		if _, err := imaging.Open(file); err != nil {
+			return IssueCorrupt, &ImageError{Path: file, Err: fmt.Errorf("%w: %w", ErrCorruptImage, err)}
+		}
+	}
+	if d.Size != entry.Size || d.Sha256 != entry.Sha256 {
+		return IssueMismatch, Errorf("内容与清单不一致: 大小 %d，清单中为 %d", d.Size, entry.Size)
+	}
+	return "", nil
+}
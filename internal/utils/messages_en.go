@@ -0,0 +1,494 @@
+package utils
+
+// enMessages 英文消息目录，键为代码中的中文原文。新增或修改中文消息时需要同步更新，缺少的消息会按原文输出
+var enMessages = map[string]string{
+	// 命令说明
+	"pickit 是一个命令行工具，提供了图片下载、还原、合成 PDF 的一些功能。": "pickit is a command line tool for downloading and restoring images and merging them into PDFs.",
+	"下载图片":         "Download images",
+	"还原图片":         "Restore images",
+	"合成 PDF":       "Merge images into a PDF",
+	"搜索本子":         "Search albums",
+	"search <关键词>": "search <keyword>",
+	"按任务文件批量下载、还原、合成 PDF": "Download, restore and merge albums in bulk from a batch file",
+	"batch <任务文件>":        "batch <batch-file>",
+	"管理批量任务，中断的任务可以继续执行":  "Manage batch jobs; interrupted jobs can be resumed",
+	"列出任务":          "List jobs",
+	"继续执行未完成的任务":    "Resume unfinished jobs",
+	"retry [任务]...": "retry [job]...",
+	"继续执行任务，不指定任务时执行所有未完成的任务": "Resume jobs; resumes all unfinished jobs when none is given",
+	"取消任务，已下载的文件会保留":          "Cancel jobs; downloaded files are kept",
+	"cancel <任务>...":     "cancel <job>...",
+	"管理订阅的本子":            "Manage subscribed albums",
+	"订阅本子，sync 时自动下载新章节": "Subscribe to an album; new chapters are downloaded on sync",
+	"add <车牌号>":          "add <aid>",
+	"取消订阅":               "Unsubscribe",
+	"remove <车牌号>...":    "remove <aid>...",
+	"列出订阅的本子":            "List subscribed albums",
+	"同步订阅，下载并还原新发布的章节":   "Sync subscriptions, downloading and restoring newly released chapters",
+	"sync [车牌号]...":      "sync [aid]...",
+	"登录并保存登录状态":          "Log in and save the session",
+	"删除已保存的登录状态":         "Remove the saved session",
+	"列出插件目录中的插件":         "List plugins in the plugin directory",
+	"查看配置":               "Inspect configuration",
+	"show [命令]":          "show [command]",
+	"输出生效的参数值及其来源，不指定命令时输出所有命令": "Print effective flag values and their sources, for all commands when none is given",
+	"查看当前 pickit 版本":       "Print the pickit version",
+	"诊断 CDN、代理、网络和输出目录的问题": "Diagnose CDN, proxy, network and output directory problems",
+	"verify <目录>":          "verify <dir>",
+	"按清单检查目录中的文件是否完整，可以只重新下载或还原有问题的文件": "Check files in a folder against its manifests, optionally re-fetching or re-restoring only broken files",
+	"info <目录>": "info <dir>",
+	"查看目录中的章节、页数、图片尺寸和格式，检查会影响合成 PDF 的问题": "Show chapters, pages, image sizes and formats in a folder, and check for problems that affect pdf",
+
+	// 参数帮助
+	"以 JSON 输出执行报告（可选）":                                              "Print the execution report as JSON (optional)",
+	"配置文件，也可以通过 PICKIT_CONFIG 指定（可选）":                                "Config file, can also be set with PICKIT_CONFIG (optional)",
+	"使用配置文件中的 profile，也可以通过 PICKIT_PROFILE 指定（可选）":                   "Profile from the config file, can also be set with PICKIT_PROFILE (optional)",
+	"插件目录（可选）":                                                       "Plugin directory (optional)",
+	"本子信息接口地址，用于自动获取图片数量和章节（可选）":                                     "Album metadata API, used to look up page counts and chapters (optional)",
+	"登录状态文件（可选）":                                                     "Session file (optional)",
+	"界面语言: zh、en，默认根据 LC_ALL、LC_MESSAGES、LANG 环境变量选择（可选）":            "Interface language: zh or en, defaults to LC_ALL, LC_MESSAGES or LANG (optional)",
+	"日志级别: debug、info、warn、error（可选）":                                "Log level: debug, info, warn or error (optional)",
+	"日志格式: console、json（可选）":                                         "Log format: console or json (optional)",
+	"日志写入该文件而不是标准错误，超过 --log-max-size 后轮转（可选）":                       "Write logs to this file instead of stderr, rotated after --log-max-size (optional)",
+	"日志文件轮转大小，单位 MB（可选）":                                             "Log file size in MB before rotation (optional)",
+	"保留的历史日志文件数量（可选）":                                                "Number of rotated log files to keep (optional)",
+	"隐藏日志中的地址和车牌号，便于分享日志（可选）":                                        "Redact URLs and album ids from logs so they can be shared (optional)",
+	"只输出错误日志，优先于 --log-level（可选）":                                    "Only log errors, overrides --log-level (optional)",
+	"输出调试日志，优先于 --log-level（可选）":                                     "Log debug messages, overrides --log-level (optional)",
+	"车牌号，支持数字、JM 编号和本子/章节链接（必传）":                                     "Album id: a number, a JM id or an album/chapter link (required)",
+	"图片 cdn 域名（必传）":                                                  "Image CDN host (required)",
+	"图片 cdn 域名，下载时必传（可选）":                                            "Image CDN host, required when downloading (optional)",
+	"保存文件夹路径（必传）":                                                    "Output directory (required)",
+	"保存文件夹路径，每个本子保存到 <output>/<车牌号>，下载时必传（可选）":                       "Output directory; each album is saved to <output>/<aid>, required when downloading (optional)",
+	"图片数量，不传时通过 --api 获取（可选）":                                        "Number of pages, looked up through --api when omitted (optional)",
+	"并发数（可选）":                                                        "Concurrency (optional)",
+	"魔法（可选）":                                                         "Proxy (optional)",
+	"魔法，优先于任务文件（可选）":                                                 "Proxy, overrides the batch file (optional)",
+	"所有本子共用的并发数，优先于任务文件（可选）":                                         "Concurrency shared by all albums, overrides the batch file (optional)",
+	"任务目录（可选）":                                                       "Job directory (optional)",
+	"任务目录，中断后再次执行同一任务文件会从上次的进度继续（可选）":                                "Job directory; running the same batch file again resumes where it stopped (optional)",
+	"同时列出已完成和已取消的任务（可选）":                                             "Also list finished and cancelled jobs (optional)",
+	"图片地址模板，支持 {cdn} {aid} {page} {ext}，页码可带偏移和补零，如 {page-1:03}（可选）": "Image URL template with {cdn} {aid} {page} {ext}; the page may have an offset and padding, e.g. {page-1:03} (optional)",
+	"图片扩展名，用于 {ext} 占位符（可选）":                                         "Image extension used for the {ext} placeholder (optional)",
+	"图片 404 时依次尝试的备选扩展名，传空值关闭（可选）":                                   "Fallback extensions tried in order on 404, pass an empty value to disable (optional)",
+	"需要下载的章节范围，如 3-7,9,12-，需要 --api（可选）":                             "Chapters to download, e.g. 3-7,9,12-, requires --api (optional)",
+	"需要处理的页面，如 1-20,25,-3,ch2:1-5，负数表示倒数（可选）":                        "Pages to process, e.g. 1-20,25,-3,ch2:1-5; negative numbers count from the end (optional)",
+	"需要跳过的页面，格式同 --pages（可选）":                                        "Pages to skip, same format as --pages (optional)",
+	"输出目录下的本子目录模板，如 \"{aid} {title}\"，为空时直接保存到输出目录（可选）":              "Album directory template under the output directory, e.g. \"{aid} {title}\"; saves directly into the output directory when empty (optional)",
+	"多章节本子的章节目录模板，需要包含 {chapter}（可选）":                                "Chapter directory template for multi-chapter albums, must contain {chapter} (optional)",
+	"还原后的图片文件名模板，支持 {aid} {page} {name} {ext}，如 {page:03}.{ext}（可选）": "File name template for restored images with {aid} {page} {name} {ext}, e.g. {page:03}.{ext} (optional)",
+	"提供下载地址的插件名（可选）":                                                 "Plugin that provides download URLs (optional)",
+	"提供切割方案的插件名（可选）":                                                 "Plugin that provides the slicing scheme (optional)",
+	"需要还原的图片文件夹路径（必传）":                                               "Directory of images to restore (required)",
+	"还原后的图片输出文件夹路径（必传）":                                              "Output directory for restored images (required)",
+	"PDF 密码（可选）":                                                     "PDF password (optional)",
+	"按标签筛选（可选）":                                                      "Filter by tag (optional)",
+	"按作者筛选（可选）":                                                      "Filter by author (optional)",
+	"排序方式: latest、views、pages、likes（可选）":                             "Sort order: latest, views, pages or likes (optional)",
+	"结果页码（可选）":                                                       "Result page (optional)",
+	"下载指定序号的结果，如 1,3-5（可选）":                                          "Download results by index, e.g. 1,3-5 (optional)",
+	"订阅文件（可选）":                                                       "Subscription file (optional)",
+	"同步后重新合成 PDF（可选）":                                                "Rebuild the PDF after syncing (optional)",
+	"把当前已有的章节视为已同步，只同步之后发布的章节，需要 --api（可选）":                          "Treat existing chapters as synced and only sync chapters released later, requires --api (optional)",
+	"持续运行，按 --interval 定期同步（可选）":                                     "Keep running and sync every --interval (optional)",
+	"持续运行时的同步间隔（可选）":                                                 "Sync interval when running continuously (optional)",
+	"用户名（必传）":                                                        "Username (required)",
+	"密码，不传时读取 PICKIT_PASSWORD 或从终端输入（可选）":                            "Password; read from PICKIT_PASSWORD or the terminal when omitted (optional)",
+	"密码（可选）": "Password (optional)",
+	"只列出计划执行的操作和输出路径，标出会被覆盖的文件，不写入任何文件（可选）":        "List planned operations and output paths, flag files that would be overwritten, write nothing (optional)",
+	"请求该车牌号的第一页检查状态码，不传时只检查连接（可选）":                 "Request the first page of this album to check the status code; only the connection is checked when omitted (optional)",
+	"检查写入权限和剩余空间的输出目录（可选）":                         "Output directory to check for write access and free space (optional)",
+	"输出目录所在磁盘至少需要的剩余空间，单位 MB（可选）":                  "Minimum free space required on the output disk, in MB (optional)",
+	"按清单中的地址重新下载缺失、损坏或内容不一致的原图（可选）":                "Re-download missing, corrupt or mismatched raw images from the urls in the manifest (optional)",
+	"按清单中的原图和切割刀数重新还原有问题的图片（可选）":                   "Re-restore broken images from the raw images and segment counts in the manifest (optional)",
+	"魔法，重新下载时使用（可选）":                               "Proxy used when re-downloading (optional)",
+	"车牌号，传入时按文件名计算每一页的切割刀数（可选）":                    "Album id; when given, compute the segment count of every page from its file name (optional)",
+	"只读取文件名或相对路径匹配的文件，如 *.jpg，可以用逗号分隔多个（可选）":       "Only read files whose name or relative path matches, e.g. *.jpg; separate multiple patterns with commas (optional)",
+	"跳过文件名或相对路径匹配的文件和章节目录，如 cover.*，可以用逗号分隔多个（可选）": "Skip files and chapter folders whose name or relative path matches, e.g. cover.*; separate multiple patterns with commas (optional)",
+	"重新下载清单中已完成的页面，默认跳过（可选）":                       "Download pages already completed in the manifest again; they are skipped by default (optional)",
+	"重新还原清单中已完成的图片，默认跳过（可选）":                       "Restore images already completed in the manifest again; they are skipped by default (optional)",
+
+	// 命令输出
+	"#\tID\t标题\t页数\t标签":                       "#\tID\tTITLE\tPAGES\tTAGS",
+	"第 %d 页，共 %d 条结果\n":                       "page %d, %d results\n",
+	"车牌号\t状态\t导出\t页数\t失败\t输出目录\t错误":           "AID\tSTATUS\tEXPORT\tPAGES\tFAILED\tOUTPUT\tERROR",
+	"共 %d 个本子，成功 %d，部分失败 %d，失败 %d\n":          "%d albums: %d ok, %d partial, %d failed\n",
+	"任务\t车牌号\t状态\t导出\t进度\t失败\t更新时间\t输出目录\t错误": "JOB\tAID\tSTATUS\tEXPORT\tPROGRESS\tFAILED\tUPDATED\tOUTPUT\tERROR",
+	"车牌号\t标题\t已同步章节\t上次同步\t输出目录":              "AID\tTITLE\tSYNCED CHAPTERS\tLAST SYNC\tOUTPUT",
+	"参数\t值\t来源":          "FLAG\tVALUE\tSOURCE",
+	"配置文件: %s\n":         "config file: %s\n",
+	"插件目录 %s 中没有插件\n":    "no plugins in plugin directory %s\n",
+	"%s\t%s\t不可用: %v\n":  "%s\t%s\tunavailable: %v\n",
+	"密码: ":               "Password: ",
+	"任务已完成":              "job already finished",
+	"没有订阅该本子":            "album is not subscribed",
+	"阶段\t输入\t输出\t大小\t说明": "STAGE\tINPUT\tOUTPUT\tSIZE\tNOTE",
+	"覆盖已有文件":             "overwrites existing file",
+	"%d 张图片无法加入 PDF":     "%d images could not be added to the PDF",
+	"清单中已完成，跳过":          "completed in manifest, skipped",
+	"共 %d 项，%d 个已有文件会被覆盖，%d 项无法执行\n":     "%d items, %d existing files would be overwritten, %d items cannot run\n",
+	"%d 项在清单中已完成，将被跳过，使用 --force 重新处理\n": "%d items are already completed in the manifest and will be skipped; use --force to redo them\n",
+	"下载 %d 页，总大小 %s，%d 页大小未知\n":          "%d pages to download, %s total, %d pages of unknown size\n",
+	"按 %d 并发预计耗时 %s\n":                   "estimated time with concurrency %d: %s\n",
+	"无法估算下载耗时":                           "download time cannot be estimated",
+	"检查\t对象\t结果\t说明":                     "CHECK\tTARGET\tRESULT\tDETAIL",
+	"%s 未通过: %s\n":                       "%s failed: %s\n",
+	"没有配置代理":                             "no proxy configured",
+	"已连接，耗时 %s":                          "connected in %s",
+	"地址不是域名，无需解析":                        "address is not a domain name, nothing to resolve",
+	"本地无法解析，使用代理时由代理解析域名":                "cannot resolve locally; the proxy resolves names when one is used",
+	"不是 HTTPS 地址":                        "not an HTTPS url",
+	"没有建立连接":                             "no connection established",
+	"，证书 %s 有效期至 %s":                     ", certificate %s valid until %s",
+	"状态码 %d，大小 %d B":                     "status %d, %d B",
+	"，使用备选格式":                            ", using a fallback format",
+	"状态码 %d":                             "status %d",
+	"已连接，没有传入车牌号，不检查示例页面":                "connected; no album given, sample page not checked",
+	"可以写入":                               "writable",
+	"当前平台无法获取剩余空间":                       "free space is not available on this platform",
+	"剩余 %d MB":                           "%d MB free",
+	"代理地址格式错误，应为 http://127.0.0.1:7890 或 socks5://127.0.0.1:1080":    "malformed proxy url, expected http://127.0.0.1:7890 or socks5://127.0.0.1:1080",
+	"无法连接代理，确认代理软件已启动并监听该端口":                                         "cannot connect to the proxy; make sure it is running and listening on this port",
+	"域名无法解析，检查 --cdn 是否正确、网络和 DNS 设置，或通过 --proxy 使用代理":               "host cannot be resolved; check --cdn, your network and DNS settings, or use --proxy",
+	"证书与域名不匹配，检查 --cdn 是否正确，或代理是否篡改了连接":                              "certificate does not match the host; check --cdn, or whether the proxy tampers with the connection",
+	"证书无法验证，检查系统时间和根证书，或代理是否替换了证书":                                   "certificate cannot be verified; check the system clock and root certificates, or whether the proxy replaces certificates",
+	"握手超时，连接可能被干扰，尝试通过 --proxy 使用代理":                                 "handshake timed out, the connection may be interfered with; try --proxy",
+	"握手失败，连接可能被重置，尝试更换 CDN 或通过 --proxy 使用代理":                         "handshake failed, the connection may have been reset; try another CDN or --proxy",
+	"请求超时，检查网络或代理，或更换 CDN":                                           "request timed out; check your network or proxy, or try another CDN",
+	"无法连接 CDN，检查 --cdn 和网络，或通过 --proxy 使用代理":                         "cannot connect to the CDN; check --cdn and your network, or use --proxy",
+	"示例页面不存在，检查车牌号、--url-template 和 --ext 是否正确":                      "sample page not found; check the album id, --url-template and --ext",
+	"访问被拒绝，执行 pickit login 登录，或更换 CDN 和代理":                           "access denied; run pickit login, or try another CDN or proxy",
+	"请求过于频繁，稍后再试或降低并发数":                                              "too many requests; try again later or lower the concurrency",
+	"CDN 服务端出错，稍后再试或更换 CDN":                                          "CDN server error; try again later or use another CDN",
+	"检查 --cdn 和 --url-template 是否正确":                                 "check --cdn and --url-template",
+	"没有写入权限，检查目录权限或换一个输出目录":                                          "no write permission; check the directory permissions or use another output directory",
+	"写入失败，磁盘可能已满或为只读":                                                "write failed, the disk may be full or read-only",
+	"无法获取剩余空间，确认目录存在且可以访问":                                           "cannot get free space; make sure the directory exists and is accessible",
+	"剩余空间少于 %d MB，清理磁盘或换一个输出目录":                                      "less than %d MB free; free up disk space or use another output directory",
+	"问题\t阶段\t文件\t说明":                                                 "PROBLEM\tSTAGE\tFILE\tDETAIL",
+	"已修复":                                                            "repaired",
+	"修复失败: %v":                                                       "repair failed: %v",
+	"检查 %d 个清单中的 %d 个文件，发现 %d 个问题，已修复 %d 个\n":                        "checked %[2]d files in %[1]d manifests, found %[3]d problems, repaired %[4]d\n",
+	"使用 --refetch 重新下载有问题的原图":                                        "use --refetch to re-download broken raw images",
+	"使用 --restore 重新还原有问题的图片":                                        "use --restore to re-restore broken images",
+	"缺少的页面没有记录在清单中，无法自动修复，需要用 download 或 restore 的 --pages 重新处理这些页面": "missing pages are not in the manifest and cannot be repaired automatically; process them again with --pages of download or restore",
+	"章节\t文件\t格式\t尺寸\t大小\t切割":                                         "CHAPTER\tFILE\tFORMAT\tDIMENSIONS\tSIZE\tSEGMENTS",
+	"非图片":                  "not an image",
+	"%d 个章节，%d 页，总大小 %s\n": "%d chapters, %d pages, %s in total\n",
+	"警告: %s\n":             "warning: %s\n",
+	"跳过 %s: %s\n":          "skipped %s: %s\n",
+	"隐藏文件":                 "hidden file",
+	"临时文件":                 "temporary file",
+	"不符合文件过滤规则":            "filtered out by include/exclude patterns",
+	"不是图片":                 "not an image",
+	"只支持两层目录":              "only two folder levels are supported",
+	"包含章节目录时根目录中的文件会被忽略": "files in the root are ignored when it contains chapter folders",
+	"%s 中没有文件": "no files in %s",
+	"%s 不是有效的图片，合成 PDF 时会被跳过":                     "%s is not a valid image and will be skipped by pdf",
+	"%s 的格式为 %s，合成 PDF 不支持，需要先用 restore 转换为 JPEG": "%s is %s, which pdf does not support; convert it to JPEG with restore first",
+	"%s 的扩展名与实际格式 %s 不一致，合成 PDF 时会被跳过":            "the extension of %s does not match its actual format %s, pdf will skip it",
+	"%s 中的图片宽度不一致 %v，合成 PDF 时会缩放到 %d":             "images in %s have different widths %v, pdf will scale them to %d",
+	"%s 中的名称不是纯数字，按字符串排序后 %s 排在 %s 之前，页面顺序可能不对":   "names in %s are not purely numeric; sorted as strings, %s comes before %s, so the page order may be wrong",
+
+	// 错误
+	"资源不存在":                               "not found",
+	"请求过于频繁":                              "too many requests",
+	"图片损坏或格式不支持":                          "corrupt or unsupported image",
+	"不支持的目录结构":                            "unsupported directory layout",
+	"切割方案与图片不匹配":                          "slicing scheme does not match the image",
+	"登录已过期，请执行 pickit login 重新登录":         "session expired, run pickit login to log in again",
+	"需要登录或登录已失效，请执行 pickit login 重新登录":    "login required or session invalid, run pickit login to log in again",
+	"参数无效":                                "invalid option",
+	"%v: 状态码 %d (url=%s)":                 "%v: status code %d (url=%s)",
+	"无效状态码: %d (url=%s)":                  "unexpected status code: %d (url=%s)",
+	"处理图片 %s 失败: %v":                      "processing image %s failed: %v",
+	"} 或 {":                               "} or {",
+	"不支持的语言 %q，可选 zh、en":                  "unsupported language %q, use zh or en",
+	"无效的日志格式 %q，可选 console、json":          "invalid log format %q, use console or json",
+	"无效的日志级别 %q，可选 debug、info、warn、error": "invalid log level %q, use debug, info, warn or error",
+	"打开日志文件失败: %w":                        "opening log file failed: %w",
+	"轮转日志文件失败: %w":                        "rotating log file failed: %w",
+	"%w: %s 包含子目录，只支持单层目录":                "%w: %s contains subdirectories, only a single directory level is supported",
+	"%w: 切割 %d 刀，图片高度为 %d":                "%w: %d slices for an image of height %d",
+	"%w: 没有配置本子信息接口":                      "%w: no album metadata API configured",
+	"HTTP请求失败: %w (url=%s)":               "HTTP request failed: %w (url=%s)",
+	"创建HTTP请求失败: %w (url=%s)":             "creating HTTP request failed: %w (url=%s)",
+	"创建请求失败: %w (url=%s)":                 "creating request failed: %w (url=%s)",
+	"请求接口失败: %w (url=%s)":                 "API request failed: %w (url=%s)",
+	"解析接口响应失败: %w (url=%s)":               "parsing API response failed: %w (url=%s)",
+	"读取接口响应失败: %w":                        "reading API response failed: %w",
+	"接口地址无效: %w":                          "invalid API URL: %w",
+	"下载失败，已重试 %d 次: %w":                   "download failed after %d retries: %w",
+	"文件创建失败: %w":                          "creating file failed: %w",
+	"文件复制失败: %w":                          "copying file failed: %w",
+	"打开图片失败: %w":                          "opening image failed: %w",
+	"保存图片失败: %w":                          "saving image failed: %w",
+	"无法读取图片":                              "cannot read image",
+	"合成 PDF 失败: %s":                       "creating PDF failed: %s",
+	"合成 PDF 失败: %w":                       "creating PDF failed: %w",
+	"没有需要合成的图片":                           "no images to merge",
+	"生成下载计划失败":                            "planning download failed",
+	"生成还原计划失败":                            "planning restore failed",
+	"生成合成计划失败":                            "planning merge failed",
+	"目录中没有有效的图片文件: %s":                    "no valid images in directory: %s",
+	"没有符合页面选择的图片":                         "no images match the page selection",
+	"没有需要下载的页面":                           "no pages to download",
+	"未传入图片数量，且没有配置本子信息接口":                 "no page count given and no album metadata API configured",
+	"本子 %d 共 %d 个章节，没有符合条件的章节":            "album %d has %d chapters, none match the selection",
+	"章节链接不支持选择章节，请传入本子编号":                 "chapter links do not support chapter selection, pass an album id",
+	"获取本子信息失败: %w":                        "fetching album metadata failed: %w",
+	"获取章节信息失败: %w":                        "fetching chapter metadata failed: %w",
+	"还原 %s 失败: %w":                        "restoring %s failed: %w",
+	"创建输出目录失败: %w":                        "creating output directory failed: %w",
+	"读取目录失败: %w":                          "reading directory failed: %w",
+	"读取子目录 %s 失败: %w":                     "reading subdirectory %s failed: %w",
+	"图片地址模板缺少 {page} 占位符: %s":             "URL template is missing the {page} placeholder: %s",
+	"未知的占位符 {%s}: %s":                     "unknown placeholder {%s}: %s",
+	"占位符 {%s} 不支持偏移和补零: %s":               "placeholder {%s} does not support offset or padding: %s",
+	"命名模板不能是绝对路径，目录请用 / 分隔: %s":           "naming templates cannot be absolute paths, separate directories with /: %s",
+	"命名模板中的目录无效: %s":                      "invalid directory in naming template: %s",
+	"命名模板需要包含 {%s} 占位符: %s":               "naming template must contain the {%s} placeholder: %s",
+	"区间不能为空":                              "range cannot be empty",
+	"无效的区间 %q: %w":                        "invalid range %q: %w",
+	"无效的区间 %q: 结束编号小于起始编号":                "invalid range %q: end is less than start",
+	"无效的编号 %q":                            "invalid number %q",
+	"无效的编号: %s":                           "invalid number: %s",
+	"编号必须大于 0":                            "number must be greater than 0",
+	"编号必须是正整数":                            "number must be a positive integer",
+	"编号超出范围":                              "number out of range",
+	"无效的页面选择 %q: 倒数页码不能作为开放区间的起点":         "invalid page selection %q: a page counted from the end cannot start an open range",
+	"无效的页面选择 %q: 章节从 1 开始":                "invalid page selection %q: chapters start at 1",
+	"无效的页面选择 %q: 页码从 1 开始":                "invalid page selection %q: pages start at 1",
+	"无效的页面选择 %q，格式如 1-20,25,-3,ch2:1-5":   "invalid page selection %q, expected a format like 1-20,25,-3,ch2:1-5",
+	"无效的车牌号 %q: %w":                       "invalid album id %q: %w",
+	"无法识别的车牌号 %q，请传入数字、JM 编号或本子/章节链接":                  "unrecognized album id %q, pass a number, a JM id or an album/chapter link",
+	"无法从链接 %q 中识别车牌号，链接路径应为 /album/<编号> 或 /photo/<编号>": "cannot find an album id in link %q, the path should be /album/<id> or /photo/<id>",
+	"链接 %q 中包含多个编号，无法确定车牌号":                            "link %q contains several ids, cannot tell which is the album id",
+	"车牌号不能为空":                                  "album id cannot be empty",
+	"只能订阅本子，不能订阅单个章节":                          "only albums can be subscribed, not single chapters",
+	"同步订阅需要配置本子信息接口":                           "syncing subscriptions requires an album metadata API",
+	"保存订阅列表失败: %w":                             "saving subscriptions failed: %w",
+	"序列化订阅列表失败: %w":                            "encoding subscriptions failed: %w",
+	"解析订阅列表失败: %w":                             "parsing subscriptions failed: %w",
+	"读取订阅列表失败: %w":                             "reading subscriptions failed: %w",
+	"任务 %s 不存在":                                "job %s does not exist",
+	"任务已取消":                                    "job cancelled",
+	"保存任务 %s 失败: %w":                           "saving job %s failed: %w",
+	"序列化任务失败: %w":                              "encoding job failed: %w",
+	"解析任务 %s 失败: %w":                           "parsing job %s failed: %w",
+	"读取任务失败: %w":                               "reading job failed: %w",
+	"读取任务目录失败: %w":                             "reading job directory failed: %w",
+	"创建任务目录失败: %w":                             "creating job directory failed: %w",
+	"批量任务文件中没有本子: %s":                          "batch file contains no albums: %s",
+	"第 %d 个本子的导出格式无效: %s，可选 raw、images、pdf":    "album %d has an invalid export format: %s, use raw, images or pdf",
+	"解析批量任务文件失败: %w":                           "parsing batch file failed: %w",
+	"读取批量任务文件失败: %w":                           "reading batch file failed: %w",
+	"配置文件中没有 profile: %s":                      "profile not found in config file: %s",
+	"解析配置文件失败: %w":                             "parsing config file failed: %w",
+	"读取配置文件失败: %w":                             "reading config file failed: %w",
+	"创建配置目录失败: %w":                             "creating config directory failed: %w",
+	"登录失败: %s":                                 "login failed: %s",
+	"登录失败: 无效状态码 %d":                           "login failed: unexpected status code %d",
+	"登录失败: 服务端没有返回 token 或 cookie":             "login failed: the server returned no token or cookie",
+	"登录请求失败: %w":                               "login request failed: %w",
+	"读取登录响应失败: %w":                             "reading login response failed: %w",
+	"登录状态中的地址无效: %w":                           "invalid URL in session: %w",
+	"保存登录状态失败: %w":                             "saving session failed: %w",
+	"序列化登录状态失败: %w":                            "encoding session failed: %w",
+	"解析登录状态失败: %w":                             "parsing session failed: %w",
+	"读取登录状态失败: %w":                             "reading session failed: %w",
+	"删除登录状态失败: %w":                             "removing session failed: %w",
+	"插件 %s 不存在于目录 %s":                          "plugin %s not found in directory %s",
+	"插件 %s 不支持 %s":                             "plugin %s does not support %s",
+	"插件 %s 响应解析失败: %w":                         "parsing response of plugin %s failed: %w",
+	"插件 %s 执行失败: %w":                           "plugin %s failed: %w",
+	"插件 %s 没有返回任何下载地址":                         "plugin %s returned no download URLs",
+	"插件 %s 没有返回文件 %s 的切割方案":                    "plugin %s returned no slicing scheme for file %s",
+	"插件 %s 返回了无效的刀数: %s=%d":                    "plugin %s returned an invalid slice count: %s=%d",
+	"插件 %s 返回错误: %s":                           "plugin %s returned an error: %s",
+	"插件获取下载地址失败: %w":                           "fetching download URLs from plugin failed: %w",
+	"插件获取切割方案失败: %w":                           "fetching slicing scheme from plugin failed: %w",
+	"序列化插件请求失败: %w":                            "encoding plugin request failed: %w",
+	"读取插件目录失败: %w":                             "reading plugin directory failed: %w",
+	"不支持的排序方式: %s，可选 latest、views、pages、likes": "unsupported sort order: %s, use latest, views, pages or likes",
+	"图片地址模板无效":                                 "invalid URL template",
+	"章节范围无效":                                   "invalid chapter range",
+	"命名模板无效":                                   "invalid naming template",
+	"加载插件失败":                                   "loading plugin failed",
+	"页面选择无效":                                   "invalid page selection",
+	"CDN 地址无效: %w":                             "invalid CDN url: %w",
+	"缺少协议或主机名: %s":                             "missing scheme or host: %s",
+	"缺少主机名: %s":                                "missing host: %s",
+	"诊断失败":                                     "diagnosis failed",
+	"诊断发现问题":                                   "diagnosis found problems",
+	"读取清单失败: %w":                               "reading manifest failed: %w",
+	"解析清单 %s 失败: %w":                           "parsing manifest %s failed: %w",
+	"查找清单失败: %w":                               "finding manifests failed: %w",
+	"序列化清单失败: %w":                              "encoding manifest failed: %w",
+	"保存清单失败: %w":                               "saving manifest failed: %w",
+	"打开文件失败: %w":                               "opening file failed: %w",
+	"读取文件失败: %w":                               "reading file failed: %w",
+	"内容与清单不一致: 大小 %d，清单中为 %d":                  "content differs from manifest: size %d, manifest has %d",
+	"%s 中没有清单，清单由 download、restore、pdf 等命令生成":  "no manifest in %s; manifests are written by download, restore, pdf and other commands",
+	"缺少第 %d 页":                                 "page %d is missing",
+	"清单中没有切割刀数，无法重新还原":                         "manifest has no segment count, cannot restore again",
+	"校验失败":                                     "verify failed",
+	"查看目录信息失败":                                 "inspecting directory failed",
+	"文件过滤规则无效":                                 "invalid file filter",
+
+	// 日志
+	"跳过文件": "skipping file",
+	"--from-now 需要通过 --api 指定接口地址": "--from-now requires --api",
+	"--quiet 和 --verbose 不能同时使用":   "--quiet and --verbose cannot be used together",
+	"--dry-run 不能与 --watch 同时使用":   "--dry-run cannot be used with --watch",
+	"PDF生成失败":                   "generating PDF failed",
+	"PDF生成成功":                   "PDF generated",
+	"下载失败":                      "download failed",
+	"下载尝试失败":                    "download attempt failed",
+	"下载序号无效":                    "invalid download index",
+	"下载搜索结果失败":                  "downloading search results failed",
+	"下载搜索结果需要 --cdn 和 --output": "downloading search results requires --cdn and --output",
+	"代理URL解析失败":                 "parsing proxy URL failed",
+	"任务中有未还原的页面，跳过合成 PDF":       "job has unrestored pages, skipping PDF",
+	"任务处理完成":                    "job finished",
+	"任务已完成，无需取消":                "job already finished, nothing to cancel",
+	"任务解析失败":                    "resolving job failed",
+	"使用配置中的参数值":                 "using flag value from configuration",
+	"保存任务失败":                    "saving job failed",
+	"保存任务进度失败":                  "saving job progress failed",
+	"保存最终结果图像":                  "saving restored image",
+	"保存登录状态失败":                  "saving session failed",
+	"保存订阅失败":                    "saving subscriptions failed",
+	"准备重试下载":                    "retrying download",
+	"分发任务到工作线程":                 "dispatching tasks to workers",
+	"创建HTTP客户端":                 "creating HTTP client",
+	"创建任务失败":                    "creating job failed",
+	"创建输出目录":                    "creating output directory",
+	"创建输出目录失败":                  "creating output directory failed",
+	"创建透明背景画布":                  "creating transparent canvas",
+	"删除不完整文件":                   "removing incomplete file",
+	"加载登录状态失败":                  "loading session failed",
+	"发现新章节":                     "new chapters found",
+	"同步订阅失败":                    "syncing subscription failed",
+	"同步间隔必须大于 0":                "sync interval must be greater than 0",
+	"启动下载工作协程":                  "starting download workers",
+	"启动工作线程":                    "starting workers",
+	"命令不存在":                     "command not found",
+	"图片信息收集完成":                  "image info collected",
+	"图片分割计算结果":                  "computed image slices",
+	"图片处理失败":                    "processing image failed",
+	"图片处理成功":                    "image processed",
+	"图片尺寸信息":                    "image dimensions",
+	"图片无需处理，直接保存":               "image needs no restoring, saving as is",
+	"图片注册失败，可能不是有效图像文件":         "registering image failed, it may not be a valid image",
+	"图片注册失败，跳过":                 "registering image failed, skipping",
+	"处理单层目录结构":                  "processing single-level directory",
+	"处理图像分段":                    "processing image slice",
+	"处理多层目录结构":                  "processing multi-level directory",
+	"处理章节":                      "processing chapter",
+	"备选地址下载成功":                  "downloaded from fallback URL",
+	"多章节PDF生成成功":                "multi-chapter PDF generated",
+	"尝试备选地址":                    "trying fallback URL",
+	"工作协程启动":                    "worker started",
+	"工作协程处理任务":                  "worker processing task",
+	"工作协程退出":                    "worker exited",
+	"工作线程处理新任务":                 "worker processing new task",
+	"工作线程开始处理任务":                "worker started processing tasks",
+	"工作线程结束":                    "worker finished",
+	"已删除登录状态":                   "session removed",
+	"已加载登录状态":                   "session loaded",
+	"开始下载文件":                    "downloading file",
+	"开始图像转PDF转换":                "converting images to PDF",
+	"开始处理图片":                    "processing image",
+	"开始复制文件内容":                  "copying file content",
+	"开始带重试的下载":                  "downloading with retries",
+	"开始批量下载任务":                  "starting batch download",
+	"开始批量处理图片":                  "starting batch image processing",
+	"所有任务已分发到工作队列":              "all tasks dispatched",
+	"所有工作协程已完成":                 "all workers finished",
+	"所有工作线程已完成处理":               "all workers finished processing",
+	"所有重试均失败":                   "all retries failed",
+	"打开任务目录失败":                  "opening job directory failed",
+	"打开原始图像":                    "opening source image",
+	"扫描插件失败":                    "scanning plugins failed",
+	"批量下载任务完成":                  "batch download finished",
+	"批量下载接收到空任务列表":              "batch download received no tasks",
+	"批量处理完成":                    "batch processing finished",
+	"插件加载完成":                    "plugin loaded",
+	"插件输出":                      "plugin output",
+	"搜索失败":                      "search failed",
+	"搜索完成":                      "search finished",
+	"搜索需要通过 --api 指定接口地址":       "search requires --api",
+	"收到退出信号，停止同步":               "received exit signal, stopping sync",
+	"文件下载成功":                    "file downloaded",
+	"无效的工作线程数量，使用默认值":           "invalid worker count, using the default",
+	"无法打开图像文件":                  "cannot open image file",
+	"日志参数无效":                    "invalid logging options",
+	"更新粘贴位置":                    "updating paste position",
+	"未配置接口地址":                   "no API configured",
+	"本子信息获取完成":                  "album metadata fetched",
+	"本轮同步完成":                    "sync round finished",
+	"正在生成PDF文件":                 "generating PDF file",
+	"正在生成多章节PDF文件":              "generating multi-chapter PDF file",
+	"正在登录":                      "logging in",
+	"没有填写图片 cdn 域名":             "no image CDN host given",
+	"没有填写输出路径":                  "no output path given",
+	"没有订阅任何本子":                  "no subscriptions",
+	"没有需要处理的图片，批量处理终止":          "no images to process, stopping",
+	"没有需要执行的任务":                 "no jobs to run",
+	"添加图片到PDF":                  "adding image to PDF",
+	"添加图片到章节":                   "adding image to chapter",
+	"添加章节下载任务":                  "adding chapter download tasks",
+	"界面语言无效":                    "invalid interface language",
+	"登录失败":                      "login failed",
+	"登录成功":                      "logged in",
+	"登录状态已保存":                   "session saved",
+	"目录中没有有效的图片文件":              "no valid images in directory",
+	"目录内容":                      "directory contents",
+	"目录扫描完成":                    "directory scanned",
+	"章节下载不完整，下次同步时重试":           "chapter download incomplete, will retry on next sync",
+	"章节中没有有效图片，跳过":              "no valid images in chapter, skipping",
+	"章节还原失败，下次同步时重试":            "restoring chapter failed, will retry on next sync",
+	"章节页面尺寸计算完成":                "chapter page size computed",
+	"等待所有工作线程完成":                "waiting for workers",
+	"获取本子信息失败":                  "fetching album metadata failed",
+	"获取目录信息失败":                  "reading directory info failed",
+	"计算分段高度":                    "computing slice height",
+	"计算图片分割数量":                  "computing slice count",
+	"订阅同步完成":                    "subscription synced",
+	"订阅成功":                      "subscribed",
+	"订阅没有新章节":                   "no new chapters",
+	"设置PDF密码保护":                 "setting PDF password",
+	"请传入关键词、--tag 或 --author":   "pass a keyword, --tag or --author",
+	"请求本子信息接口":                  "requesting album metadata API",
+	"读取任务失败":                    "reading job failed",
+	"读取批量任务失败":                  "reading batch file failed",
+	"读取订阅失败":                    "reading subscriptions failed",
+	"读取配置文件失败":                  "reading config file failed",
+	"调整worker数量":                "adjusting worker count",
+	"调整源图Y坐标为0":                 "clamping source Y to 0",
+	"调用插件":                      "calling plugin",
+	"路径处理完成":                    "path resolved",
+	"跳过任务":                      "skipping job",
+	"跳过无法读取的任务":                 "skipping unreadable job",
+	"车牌号无效":                     "invalid album id",
+	"还原图片失败":                    "restoring images failed",
+	"进入持续同步模式":                  "entering continuous sync mode",
+	"退出登录失败":                    "logout failed",
+	"配置HTTP代理":                  "configuring HTTP proxy",
+	"配置中的参数值无效":                 "invalid flag value in configuration",
+	"重试下载成功":                    "download succeeded after retry",
+	"页面使用了备选格式":                 "page uses a fallback format",
+	"页面尺寸计算完成":                  "page size computed",
+	"页面选择完成":                    "pages selected",
+	"探测下载地址完成":                  "probing download urls finished",
+	"测量下载速度失败，无法估算耗时":           "measuring download speed failed, cannot estimate time",
+	"诊断未通过":                     "check failed",
+	"更新清单失败":                    "updating manifest failed",
+	"记录清单失败":                    "recording manifest entry failed",
+	"清单已更新":                     "manifest updated",
+	"校验完成":                      "verification finished",
+	"读取清单失败，重新处理该目录的页面":         "reading manifest failed, processing the pages in its directory again",
+	"跳过清单中已完成的页面":               "skipping pages already completed in the manifest",
+	"查找未完成的任务失败":                "finding unfinished job failed",
+}
@@ -0,0 +1,355 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AlbumMeta 本子信息
+type AlbumMeta struct {
+	Id       int
+	Title    string
+	Authors  []string
+	Tags     []string
+	Chapters []ChapterMeta // 按顺序排列的章节，单章节本子只有一个章节，其编号与本子相同
+}
+
+// PageCount 所有章节的总页数
+func (a *AlbumMeta) PageCount() int {
+	total := 0
+	for _, c := range a.Chapters {
+		total += c.PageCount
+	}
+	return total
+}
+
+// ChapterMeta 章节信息
+type ChapterMeta struct {
+	Id        int // 章节编号（photo id），下载和还原时作为车牌号使用
+	Title     string
+	Sort      int // 章节序号，从 1 开始
+	PageCount int
+	Images    []string // 图片文件名
+}
+
+// MetadataProvider 本子信息来源
+type MetadataProvider interface {
+	// Album 获取本子信息，包括章节列表和每个章节的页数
+	Album(ctx context.Context, id int) (*AlbumMeta, error)
+	// Chapter 获取单个章节的信息
+	Chapter(ctx context.Context, id int) (*ChapterMeta, error)
+}
+
+/*
+HTTPMetadataProvider 通过 HTTP/JSON 接口获取本子信息。
+
+	GET {api}/album?id=<本子编号>
+	{
+	  "id": "123",
+	  "name": "标题",
+	  "author": ["作者"],
+	  "tags": ["标签"],
+	  "series": [{"id": "124", "name": "第1话", "sort": "1"}]
+	}
+
+	GET {api}/chapter?id=<章节编号>
+	{
+	  "id": "124",
+	  "name": "第1话",
+	  "series_id": "123",
+	  "images": ["00001.webp", "00002.webp"]
+	}
+
+编号既可以是数字也可以是字符串。series 为空表示单章节本子，此时章节编号与本子编号相同。
+*/
+type HTTPMetadataProvider struct {
+	BaseUrl string
+	Client  *http.Client
+}
+
+// NewHTTPMetadataProvider 创建 HTTP 本子信息来源
+func NewHTTPMetadataProvider(baseUrl, proxy string) *HTTPMetadataProvider {
+	return &HTTPMetadataProvider{
+		BaseUrl: strings.TrimSuffix(baseUrl, "/"),
+		Client:  NewHTTPClient(proxy, 15*time.Second),
+	}
+}
+
+// flexInt 兼容字符串和数字两种形式的编号
+type flexInt int
+
+func (f *flexInt) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	if len(data) == 0 || string(data) == "null" {
+		*f = 0
+		return nil
+	}
+	n, err := strconv.Atoi(string(data))
+	if err != nil {
+		return Errorf("无效的编号: %s", data)
+	}
+	*f = flexInt(n)
+	return nil
+}
+
+type albumResponse struct {
+	Id     flexInt  `json:"id"`
+	Name   string   `json:"name"`
+	Author []string `json:"author"`
+	Tags   []string `json:"tags"`
+	Series []struct {
+		Id   flexInt `json:"id"`
+		Name string  `json:"name"`
+		Sort flexInt `json:"sort"`
+	} `json:"series"`
+}
+
+type chapterResponse struct {
+	Id       flexInt  `json:"id"`
+	Name     string   `json:"name"`
+	SeriesId flexInt  `json:"series_id"`
+	Images   []string `json:"images"`
+}
+
+func (p *HTTPMetadataProvider) Album(ctx context.Context, id int) (*AlbumMeta, error) {
+	var resp albumResponse
+	if err := p.getJSON(ctx, "album", id, &resp); err != nil {
+		return nil, err
+	}
+
+	album := &AlbumMeta{
+		Id:      int(resp.Id),
+		Title:   resp.Name,
+		Authors: resp.Author,
+		Tags:    resp.Tags,
+	}
+	if album.Id == 0 {
+		album.Id = id
+	}
+
+	// 单章节本子
+	if len(resp.Series) == 0 {
+		chapter, err := p.Chapter(ctx, album.Id)
+		if err != nil {
+			return nil, err
+		}
+		chapter.Sort = 1
+		if chapter.Title == "" {
+			chapter.Title = album.Title
+		}
+		album.Chapters = []ChapterMeta{*chapter}
+	}
+
+	for i, s := range resp.Series {
+		chapter, err := p.Chapter(ctx, int(s.Id))
+		if err != nil {
+			return nil, err
+		}
+		chapter.Sort = int(s.Sort)
+		if chapter.Sort == 0 {
+			chapter.Sort = i + 1
+		}
+		if s.Name != "" {
+			chapter.Title = s.Name
+		}
+		album.Chapters = append(album.Chapters, *chapter)
+	}
+
+	LoggerFrom(ctx).Info("本子信息获取完成",
+		Int("aid", album.Id),
+		Str("title", album.Title),
+		Int("chapters", len(album.Chapters)),
+		Int("pages", album.PageCount()))
+	return album, nil
+}
+
+func (p *HTTPMetadataProvider) Chapter(ctx context.Context, id int) (*ChapterMeta, error) {
+	var resp chapterResponse
+	if err := p.getJSON(ctx, "chapter", id, &resp); err != nil {
+		return nil, err
+	}
+
+	chapter := &ChapterMeta{
+		Id:        int(resp.Id),
+		Title:     resp.Name,
+		PageCount: len(resp.Images),
+		Images:    resp.Images,
+	}
+	if chapter.Id == 0 {
+		chapter.Id = id
+	}
+	return chapter, nil
+}
+
+// getJSON 按编号请求接口并解析 JSON 响应
+func (p *HTTPMetadataProvider) getJSON(ctx context.Context, endpoint string, id int, v interface{}) error {
+	return p.get(ctx, endpoint, url.Values{"id": {strconv.Itoa(id)}}, v)
+}
+
+// get 请求接口并解析 JSON 响应
+func (p *HTTPMetadataProvider) get(ctx context.Context, endpoint string, params url.Values, v interface{}) error {
+	if p.BaseUrl == "" {
+		return Errorf("未配置接口地址")
+	}
+
+	reqUrl := fmt.Sprintf("%s/%s?%s", p.BaseUrl, endpoint, params.Encode())
+	LoggerFrom(ctx).Debug("请求本子信息接口", Str("url", reqUrl))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqUrl, nil)
+	if err != nil {
+		return Errorf("创建请求失败: %w (url=%s)", err, reqUrl)
+	}
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return Errorf("请求接口失败: %w (url=%s)", err, reqUrl)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return newHTTPError(reqUrl, resp)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Errorf("读取接口响应失败: %w", err)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return Errorf("解析接口响应失败: %w (url=%s)", err, reqUrl)
+	}
+	return nil
+}
+
+// SearchQuery 搜索条件
+type SearchQuery struct {
+	Query  string
+	Tag    string
+	Author string
+	Sort   string // latest、views、pages、likes
+	Page   int    // 从 1 开始
+}
+
+// SearchItem 搜索结果中的本子
+type SearchItem struct {
+	Id        int      `json:"id"`
+	Title     string   `json:"title"`
+	Authors   []string `json:"authors"`
+	Tags      []string `json:"tags"`
+	PageCount int      `json:"page_count"`
+}
+
+// SearchResult 搜索结果
+type SearchResult struct {
+	Total int          `json:"total"`
+	Page  int          `json:"page"`
+	Items []SearchItem `json:"items"`
+}
+
+// Searcher 本子搜索
+type Searcher interface {
+	Search(ctx context.Context, query SearchQuery) (*SearchResult, error)
+}
+
+// searchSorts 排序方式到接口参数的映射
+var searchSorts = map[string]string{
+	"latest": "mr",
+	"views":  "mv",
+	"pages":  "mp",
+	"likes":  "tf",
+}
+
+type searchResponse struct {
+	Total   flexInt `json:"total"`
+	Content []struct {
+		Id         flexInt         `json:"id"`
+		Name       string          `json:"name"`
+		Author     json.RawMessage `json:"author"`
+		Tags       []string        `json:"tags"`
+		ImageCount flexInt         `json:"image_count"`
+	} `json:"content"`
+}
+
+/*
+Search 搜索本子。
+
+	GET {api}/search?search_query=<关键词>&tag=<标签>&author=<作者>&o=<mr|mv|mp|tf>&page=<页码>
+	{
+	  "total": "120",
+	  "content": [{"id": "123", "name": "标题", "author": "作者", "tags": ["标签"], "image_count": "30"}]
+	}
+
+author 既可以是字符串也可以是字符串数组，image_count 可以省略。
+*/
+func (p *HTTPMetadataProvider) Search(ctx context.Context, query SearchQuery) (*SearchResult, error) {
+	params := url.Values{}
+	if query.Query != "" {
+		params.Set("search_query", query.Query)
+	}
+	if query.Tag != "" {
+		params.Set("tag", query.Tag)
+	}
+	if query.Author != "" {
+		params.Set("author", query.Author)
+	}
+	if query.Sort != "" {
+		o, ok := searchSorts[query.Sort]
+		if !ok {
+			return nil, Errorf("不支持的排序方式: %s，可选 latest、views、pages、likes", query.Sort)
+		}
+		params.Set("o", o)
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	params.Set("page", strconv.Itoa(query.Page))
+
+	var resp searchResponse
+	if err := p.get(ctx, "search", params, &resp); err != nil {
+		return nil, err
+	}
+
+	result := &SearchResult{
+		Total: int(resp.Total),
+		Page:  query.Page,
+		Items: make([]SearchItem, 0, len(resp.Content)),
+	}
+	for _, c := range resp.Content {
+		result.Items = append(result.Items, SearchItem{
+			Id:        int(c.Id),
+			Title:     c.Name,
+			Authors:   parseAuthors(c.Author),
+			Tags:      c.Tags,
+			PageCount: int(c.ImageCount),
+		})
+	}
+
+	LoggerFrom(ctx).Info("搜索完成",
+		Str("query", query.Query),
+		Int("page", result.Page),
+		Int("total", result.Total),
+		Int("items", len(result.Items)))
+	return result, nil
+}
+
+// parseAuthors 兼容字符串和字符串数组两种形式的作者
+func parseAuthors(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var authors []string
+	if err := json.Unmarshal(raw, &authors); err == nil {
+		return authors
+	}
+	var author string
+	if err := json.Unmarshal(raw, &author); err == nil && author != "" {
+		return []string{author}
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// newMetadataServer 启动本地的本子信息接口，routes 为 "路径?参数" 到响应的映射
+func newMetadataServer(t *testing.T, routes map[string]string) *HTTPMetadataProvider {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := routes[r.URL.Path+"?"+r.URL.RawQuery]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return &HTTPMetadataProvider{BaseUrl: srv.URL, Client: srv.Client()}
+}
+
+func TestHTTPMetadataProviderAlbum(t *testing.T) {
+	p := newMetadataServer(t, map[string]string{
+		"/album?id=100": `{"id": "100", "name": "标题", "author": ["作者"], "tags": ["a", "b"],
+			"series": [{"id": "101", "name": "第1话", "sort": "1"}, {"id": 102, "name": "", "sort": 0}]}`,
+		"/chapter?id=101": `{"id": "101", "name": "ignored", "series_id": "100", "images": ["00001.webp", "00002.webp"]}`,
+		"/chapter?id=102": `{"id": 102, "name": "第2话", "series_id": 100, "images": ["00001.webp", "00002.webp", "00003.webp"]}`,
+	})
+
+	album, err := p.Album(context.Background(), 100)
+	if err != nil {
+		t.Fatalf("Album: %v", err)
+	}
+	if album.Id != 100 || album.Title != "标题" || len(album.Authors) != 1 || len(album.Tags) != 2 {
+		t.Errorf("album = %+v", album)
+	}
+	if len(album.Chapters) != 2 || album.PageCount() != 5 {
+		t.Fatalf("chapters = %+v, pages = %d", album.Chapters, album.PageCount())
+	}
+
+	first, second := album.Chapters[0], album.Chapters[1]
+	if first.Id != 101 || first.Sort != 1 || first.Title != "第1话" || first.PageCount != 2 {
+		t.Errorf("first chapter = %+v", first)
+	}
+	// sort 为 0 时按顺序编号，series 中没有名称时使用章节接口的名称
+	if second.Id != 102 || second.Sort != 2 || second.Title != "第2话" || second.PageCount != 3 {
+		t.Errorf("second chapter = %+v", second)
+	}
+}
+
+func TestHTTPMetadataProviderSingleChapter(t *testing.T) {
+	p := newMetadataServer(t, map[string]string{
+		"/album?id=200":   `{"id": 200, "name": "单章节", "series": []}`,
+		"/chapter?id=200": `{"id": "200", "name": "", "images": ["00001.webp"]}`,
+	})
+
+	album, err := p.Album(context.Background(), 200)
+	if err != nil {
+		t.Fatalf("Album: %v", err)
+	}
+	if len(album.Chapters) != 1 {
+		t.Fatalf("chapters = %+v", album.Chapters)
+	}
+	chapter := album.Chapters[0]
+	if chapter.Id != 200 || chapter.Sort != 1 || chapter.Title != "单章节" || chapter.PageCount != 1 {
+		t.Errorf("chapter = %+v", chapter)
+	}
+}
+
+func TestHTTPMetadataProviderChapter(t *testing.T) {
+	p := newMetadataServer(t, map[string]string{
+		"/chapter?id=300": `{"name": "无编号", "images": ["a.webp", "b.webp", "c.webp", "d.webp"]}`,
+	})
+
+	chapter, err := p.Chapter(context.Background(), 300)
+	if err != nil {
+		t.Fatalf("Chapter: %v", err)
+	}
+	// 响应中没有编号时使用请求的编号
+	if chapter.Id != 300 || chapter.PageCount != 4 || chapter.Images[3] != "d.webp" {
+		t.Errorf("chapter = %+v", chapter)
+	}
+}
+
+func TestHTTPMetadataProviderErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		want       error
+	}{
+		{"not found", http.StatusNotFound, "", ErrNotFound},
+		{"rate limited", http.StatusTooManyRequests, "3", ErrRateLimited},
+		{"unauthorized", http.StatusUnauthorized, "", ErrUnauthorized},
+		{"forbidden", http.StatusForbidden, "", ErrUnauthorized},
+		{"server error", http.StatusInternalServerError, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+			p := &HTTPMetadataProvider{BaseUrl: srv.URL, Client: srv.Client()}
+
+			_, err := p.Album(context.Background(), 1)
+			var httpErr *HTTPError
+			if !errors.As(err, &httpErr) {
+				t.Fatalf("err = %v, want *HTTPError", err)
+			}
+			if httpErr.StatusCode != tt.status {
+				t.Errorf("StatusCode = %d, want %d", httpErr.StatusCode, tt.status)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && httpErr.Unwrap() != nil {
+				t.Errorf("err = %v, want no category", err)
+			}
+			if tt.retryAfter != "" && httpErr.RetryAfter != 3*time.Second {
+				t.Errorf("RetryAfter = %v, want 3s", httpErr.RetryAfter)
+			}
+		})
+	}
+}
+
+func TestHTTPMetadataProviderInvalidResponse(t *testing.T) {
+	p := newMetadataServer(t, map[string]string{
+		"/album?id=1":   `not json`,
+		"/chapter?id=2": `{"id": "abc"}`,
+	})
+
+	if _, err := p.Album(context.Background(), 1); err == nil {
+		t.Error("Album with invalid JSON: want error")
+	}
+	if _, err := p.Chapter(context.Background(), 2); err == nil {
+		t.Error("Chapter with invalid id: want error")
+	}
+	if _, err := (&HTTPMetadataProvider{}).Album(context.Background(), 1); err == nil {
+		t.Error("Album without BaseUrl: want error")
+	}
+}
+
+func TestHTTPMetadataProviderSearch(t *testing.T) {
+	var got url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search" {
+			http.NotFound(w, r)
+			return
+		}
+		got = r.URL.Query()
+		_, _ = w.Write([]byte(`{"total": "42", "content": [
+			{"id": "101", "name": "第一本", "author": "作者甲", "tags": ["a"], "image_count": "30"},
+			{"id": 102, "name": "第二本", "author": ["作者乙", "作者丙"], "tags": []}
+		]}`))
+	}))
+	defer srv.Close()
+	p := &HTTPMetadataProvider{BaseUrl: srv.URL, Client: srv.Client()}
+
+	result, err := p.Search(context.Background(), SearchQuery{Query: "关键词", Tag: "标签", Author: "作者", Sort: "views", Page: 3})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	want := url.Values{
+		"search_query": {"关键词"},
+		"tag":          {"标签"},
+		"author":       {"作者"},
+		"o":            {"mv"},
+		"page":         {"3"},
+	}
+	if got.Encode() != want.Encode() {
+		t.Errorf("query = %v, want %v", got, want)
+	}
+
+	if result.Total != 42 || result.Page != 3 || len(result.Items) != 2 {
+		t.Fatalf("result = %+v", result)
+	}
+	first, second := result.Items[0], result.Items[1]
+	if first.Id != 101 || first.Title != "第一本" || len(first.Authors) != 1 || first.Authors[0] != "作者甲" || first.PageCount != 30 {
+		t.Errorf("first = %+v", first)
+	}
+	// author 为数组、省略 image_count
+	if second.Id != 102 || len(second.Authors) != 2 || second.Authors[1] != "作者丙" || second.PageCount != 0 {
+		t.Errorf("second = %+v", second)
+	}
+}
+
+func TestHTTPMetadataProviderSearchDefaults(t *testing.T) {
+	var got url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query()
+		_, _ = w.Write([]byte(`{"total": 0, "content": []}`))
+	}))
+	defer srv.Close()
+	p := &HTTPMetadataProvider{BaseUrl: srv.URL, Client: srv.Client()}
+
+	result, err := p.Search(context.Background(), SearchQuery{Tag: "标签"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	// 没有传入的条件不出现在参数中，页码默认为 1
+	want := url.Values{"tag": {"标签"}, "page": {"1"}}
+	if got.Encode() != want.Encode() {
+		t.Errorf("query = %v, want %v", got, want)
+	}
+	if result.Page != 1 || result.Items == nil || len(result.Items) != 0 {
+		t.Errorf("result = %+v", result)
+	}
+
+	for sort, o := range searchSorts {
+		if _, err := p.Search(context.Background(), SearchQuery{Sort: sort}); err != nil {
+			t.Fatalf("Search sort %s: %v", sort, err)
+		}
+		if got.Get("o") != o {
+			t.Errorf("sort %s: o = %q, want %q", sort, got.Get("o"), o)
+		}
+	}
+	if _, err := p.Search(context.Background(), SearchQuery{Sort: "random"}); err == nil {
+		t.Error("Search with unknown sort: want error")
+	}
+}
@@ -0,0 +1,278 @@
+package utils
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 默认的命名模板，与之前的目录结构保持一致
+const (
+	DefaultAlbumDirTemplate   = ""             // 为空时直接保存到输出目录
+	DefaultChapterDirTemplate = "{chapter}"    // 多章节本子的章节目录
+	DefaultPageNameTemplate   = "{name}.{ext}" // 页面文件名
+)
+
+const (
+	maxTitleLength    = 60  // 标题占位符最多保留的字符数
+	maxFilenameLength = 200 // 单个路径组件最多保留的字节数，大部分文件系统的上限为 255
+)
+
+/*
+NameTemplate 输出路径模板。
+支持的占位符:
+
+	{aid}      车牌号
+	{title}    本子标题，需要通过 --api 获取，超过 60 个字符时截断
+	{chapter}  章节序号，单章节本子为 1
+	{page}     页码，从 1 开始
+	{name}     原文件名（不带扩展名）
+	{ext}      扩展名（不带点）
+
+数字占位符支持偏移和补零，如 {page:03}、{chapter-1}。模板中可以用 / 分隔多级目录，
+但不能是绝对路径，也不能包含 ..；占位符的值会被清理成合法的文件名，不会产生新的目录层级。
+*/
+type NameTemplate struct {
+	raw   string
+	parts []templatePart
+}
+
+// NameValues 渲染命名模板时使用的值
+type NameValues struct {
+	Aid     int
+	Title   string
+	Chapter int
+	Page    int
+	Name    string
+	Ext     string
+}
+
+// ParseNameTemplate 解析命名模板，required 中的占位符至少要出现一个
+func ParseNameTemplate(raw string, required ...string) (*NameTemplate, error) {
+	tpl := &NameTemplate{raw: raw}
+	if raw == "" {
+		return tpl, nil
+	}
+	if strings.HasPrefix(raw, "/") || strings.Contains(raw, "\\") {
+		return nil, Errorf("命名模板不能是绝对路径，目录请用 / 分隔: %s", raw)
+	}
+	for _, segment := range strings.Split(raw, "/") {
+		if segment == ".." || segment == "." || segment == "" {
+			return nil, Errorf("命名模板中的目录无效: %s", raw)
+		}
+	}
+
+	found := make(map[string]bool)
+	last := 0
+	for _, m := range placeholderPattern.FindAllStringSubmatchIndex(raw, -1) {
+		if m[0] > last {
+			tpl.parts = append(tpl.parts, templatePart{literal: raw[last:m[0]]})
+		}
+		last = m[1]
+
+		part := templatePart{name: raw[m[2]:m[3]]}
+		switch part.name {
+		case "title", "name", "ext":
+			if m[4] >= 0 || m[6] >= 0 {
+				return nil, Errorf("占位符 {%s} 不支持偏移和补零: %s", part.name, raw)
+			}
+		case "aid", "chapter", "page":
+			if m[4] >= 0 {
+				part.offset, _ = strconv.Atoi(raw[m[4]:m[5]])
+			}
+			if m[6] >= 0 {
+				part.width, _ = strconv.Atoi(raw[m[6]:m[7]])
+			}
+		default:
+			return nil, Errorf("未知的占位符 {%s}: %s", part.name, raw)
+		}
+		found[part.name] = true
+		tpl.parts = append(tpl.parts, part)
+	}
+	if last < len(raw) {
+		tpl.parts = append(tpl.parts, templatePart{literal: raw[last:]})
+	}
+
+	if len(required) > 0 {
+		ok := false
+		for _, name := range required {
+			ok = ok || found[name]
+		}
+		if !ok {
+			return nil, Errorf("命名模板需要包含 {%s} 占位符: %s", strings.Join(required, T("} 或 {")), raw)
+		}
+	}
+	return tpl, nil
+}
+
+// String 返回模板原文
+func (t *NameTemplate) String() string {
+	return t.raw
+}
+
+// Empty 判断模板是否为空
+func (t *NameTemplate) Empty() bool {
+	return t == nil || t.raw == ""
+}
+
+// Render 渲染模板，返回以 / 分隔的相对路径，每一级都是合法的文件名
+func (t *NameTemplate) Render(v NameValues) string {
+	var sb strings.Builder
+	for _, part := range t.parts {
+		switch part.name {
+		case "":
+			sb.WriteString(part.literal)
+		case "aid":
+			sb.WriteString(fmt.Sprintf("%0*d", part.width, v.Aid+part.offset))
+		case "chapter":
+			sb.WriteString(fmt.Sprintf("%0*d", part.width, v.Chapter+part.offset))
+		case "page":
+			sb.WriteString(fmt.Sprintf("%0*d", part.width, v.Page+part.offset))
+		case "title":
+			sb.WriteString(placeholderValue(truncateRunes(v.Title, maxTitleLength)))
+		case "name":
+			sb.WriteString(placeholderValue(v.Name))
+		case "ext":
+			sb.WriteString(placeholderValue(strings.TrimPrefix(v.Ext, ".")))
+		}
+	}
+
+	segments := strings.Split(sb.String(), "/")
+	for i, segment := range segments {
+		segments[i] = SanitizeFilename(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// placeholderValue 清理占位符的值，去掉其中的路径分隔符
+func placeholderValue(value string) string {
+	return strings.NewReplacer("/", "_", "\\", "_").Replace(value)
+}
+
+// Naming 专辑目录和章节目录的命名模板。下载的原图始终保持原文件名，
+// 还原时按原文件名计算切割刀数，页面文件名模板只用于还原后的图片
+type Naming struct {
+	AlbumDir   *NameTemplate
+	ChapterDir *NameTemplate
+}
+
+// ParseNaming 解析命名模板，章节目录需要包含 {chapter}
+func ParseNaming(albumDir, chapterDir string) (*Naming, error) {
+	album, err := ParseNameTemplate(albumDir)
+	if err != nil {
+		return nil, err
+	}
+	chapter, err := ParseNameTemplate(chapterDir, "chapter")
+	if err != nil {
+		return nil, err
+	}
+	return &Naming{AlbumDir: album, ChapterDir: chapter}, nil
+}
+
+// ParsePageNameTemplate 解析页面文件名模板，需要包含 {page} 或 {name}，避免不同页面重名
+func ParsePageNameTemplate(raw string) (*NameTemplate, error) {
+	if raw == "" {
+		raw = DefaultPageNameTemplate
+	}
+	return ParseNameTemplate(raw, "page", "name")
+}
+
+// AlbumPath 专辑目录，模板为空或渲染结果为空时返回 output
+func (n *Naming) AlbumPath(output string, v NameValues) string {
+	if n == nil || n.AlbumDir.Empty() {
+		return output
+	}
+	return joinRendered(output, n.AlbumDir.Render(v))
+}
+
+// ChapterPath 章节目录
+func (n *Naming) ChapterPath(albumPath string, v NameValues) string {
+	if n == nil || n.ChapterDir.Empty() {
+		return path.Join(albumPath, strconv.Itoa(v.Chapter))
+	}
+	return joinRendered(albumPath, n.ChapterDir.Render(v))
+}
+
+// PagePath 按页面文件名模板构建路径，模板为 nil 时使用 {name}.{ext}
+func PagePath(tpl *NameTemplate, dir string, v NameValues) string {
+	if tpl.Empty() {
+		return path.Join(dir, SanitizeFilename(v.Name+"."+strings.TrimPrefix(v.Ext, ".")))
+	}
+	return joinRendered(dir, tpl.Render(v))
+}
+
+// joinRendered 拼接渲染结果，忽略渲染后为空的目录
+func joinRendered(base, rendered string) string {
+	elems := []string{base}
+	for _, segment := range strings.Split(rendered, "/") {
+		if segment != "" {
+			elems = append(elems, segment)
+		}
+	}
+	return path.Join(elems...)
+}
+
+// windowsReserved Windows 中不能作为文件名的设备名
+var windowsReserved = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// SanitizeFilename 把任意字符串清理成在 Linux 和 Windows 上都合法的单个文件名:
+// 替换路径分隔符、保留字符和控制字符，去掉首尾空格和末尾的点，避开 Windows 设备名，并限制长度。
+// 结果为 . 或 .. 时替换为 _，为空时返回空字符串。
+func SanitizeFilename(name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		switch {
+		case r == '/' || r == '\\' || r == ':' || r == '*' || r == '?' || r == '"' || r == '<' || r == '>' || r == '|':
+			sb.WriteRune('_')
+		case unicode.IsControl(r) || r == utf8.RuneError:
+			// 丢弃控制字符和无效字符
+		default:
+			sb.WriteRune(r)
+		}
+	}
+
+	result := strings.TrimSpace(sb.String())
+	result = truncateBytes(result, maxFilenameLength)
+	result = strings.TrimRight(result, ". ")
+	if result == "" {
+		if strings.Trim(name, ". ") == "" && name != "" {
+			return "_"
+		}
+		return ""
+	}
+
+	base := strings.ToUpper(result)
+	if i := strings.IndexByte(base, '.'); i >= 0 {
+		base = base[:i]
+	}
+	if windowsReserved[base] {
+		result = "_" + result
+	}
+	return result
+}
+
+// truncateRunes 最多保留 n 个字符
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return strings.TrimSpace(string([]rune(s)[:n]))
+}
+
+// truncateBytes 在字符边界处截断，最多保留 n 个字节
+func truncateBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -1,10 +1,14 @@
 package utils
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/jung-kurt/gofpdf"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 type imageInfo struct {
@@ -12,39 +16,63 @@ type imageInfo struct {
 	path          string
 }
 
-func ConvertImagesToPDF(dir, output, password string) error {
+func ConvertImagesToPDF(ctx context.Context, dir, output, password string, events EventHandler) error {
+	logger := LoggerFrom(ctx)
 	// 记录转换开始
-	LogInfo("开始图像转PDF转换",
+	logger.Info("开始图像转PDF转换",
 		Str("input_dir", dir),
 		Str("output_file", output),
 	)
 
-	files, err := GetDirInfo(dir)
+	files, skipped, err := GetDirInfo(dir, nil)
 	if err != nil {
-		LogError("获取目录信息失败", Err(err))
+		logger.Error("获取目录信息失败", Err(err))
 		return err
 	}
+	LogSkipped(ctx, skipped)
 
-	LogDebug("目录扫描完成",
-		Int("目录数量", len(files)),
-		Int("总文件数", totalFileCount(files)),
+	return ConvertDirInfoToPDF(ctx, files, output, password, events)
+}
+
+// ConvertDirInfoToPDF 将目录信息中的图片合成 PDF，多个章节时按章节添加书签。处理过程中向 events（可以为 nil）发送 pdf 阶段的事件，
+// 单项事件的 Index 为图片的序号，job_finished 的 Err 为合成失败的原因。ctx 取消时停止合成并返回 ctx.Err()
+func ConvertDirInfoToPDF(ctx context.Context, files []DirInfo, output, password string, events EventHandler) error {
+	start := time.Now()
+	ev := &pdfEvents{handler: events, total: totalFileCount(files)}
+	emit(events, Event{Type: EventJobStarted, Stage: StagePdf, Index: -1, Total: ev.total, Output: output})
+	err := convertDirInfoToPDF(ctx, files, output, password, ev)
+	emit(events, Event{Type: EventJobFinished, Stage: StagePdf, Index: -1, Total: ev.total, Output: output,
+		Succeeded: ev.succeeded, Failed: ev.failed, Err: err, Duration: time.Since(start)})
+	return err
+}
+
+func convertDirInfoToPDF(ctx context.Context, files []DirInfo, output, password string, ev *pdfEvents) error {
+	logger := LoggerFrom(ctx)
+	if len(files) == 0 {
+		logger.Error("没有需要合成的图片")
+		return Errorf("没有需要合成的图片")
+	}
+
+	logger.Debug("目录扫描完成",
+		Int("dirs", len(files)),
+		Int("total_files", totalFileCount(files)),
 	)
 
 	// 确保输出目录存在
-	if err := ensureOutputDir(output); err != nil {
-		LogError("创建输出目录失败", Err(err))
+	if err := ensureOutputDir(ctx, output); err != nil {
+		logger.Error("创建输出目录失败", Err(err))
 		return err
 	}
 
 	// 单层目录处理
 	if len(files) == 1 {
-		LogDebug("处理单层目录结构")
+		logger.Debug("处理单层目录结构")
 		info := files[0]
 		imageList := info.Files
 
-		LogDebug("目录内容",
-			Str("目录名", info.Name),
-			Int("图片数量", len(imageList)),
+		logger.Debug("目录内容",
+			Str("dir", info.Name),
+			Int("images", len(imageList)),
 		)
 
 		pdf := gofpdf.NewCustom(&gofpdf.InitType{
@@ -54,9 +82,12 @@ func ConvertImagesToPDF(dir, output, password string) error {
 		// 收集图片信息
 		imageInfos := make([]imageInfo, 0, len(imageList))
 		for _, file := range imageList {
-			imgInfo := pdf.RegisterImage(file, "")
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			imgInfo := ev.register(pdf, file)
 			if imgInfo == nil {
-				LogWarn("图片注册失败，可能不是有效图像文件", Str("file", file))
+				logger.Warn("图片注册失败，可能不是有效图像文件", Str("file", file))
 				continue
 			}
 			imageInfos = append(imageInfos, imageInfo{
@@ -66,11 +97,11 @@ func ConvertImagesToPDF(dir, output, password string) error {
 			})
 		}
 
-		LogDebug("图片信息收集完成", Int("有效图片数", len(imageInfos)))
+		logger.Debug("图片信息收集完成", Int("valid_images", len(imageInfos)))
 
 		if len(imageInfos) == 0 {
-			LogError("目录中没有有效的图片文件", Str("dir", info.Name))
-			return fmt.Errorf("目录中没有有效的图片文件: %s", info.Name)
+			logger.Error("目录中没有有效的图片文件", Str("dir", info.Name))
+			return Errorf("目录中没有有效的图片文件: %s", info.Name)
 		}
 
 		// 计算最终尺寸
@@ -86,7 +117,7 @@ func ConvertImagesToPDF(dir, output, password string) error {
 			finalHeight += img.height
 		}
 
-		LogDebug("页面尺寸计算完成",
+		logger.Debug("页面尺寸计算完成",
 			Float64("width", finalWidth),
 			Float64("height", finalHeight),
 		)
@@ -100,7 +131,7 @@ func ConvertImagesToPDF(dir, output, password string) error {
 		// 添加图片
 		var currentY float64
 		for i, img := range adjustedImages {
-			LogDebug("添加图片到PDF",
+			logger.Debug("添加图片到PDF",
 				Str("file", img.path),
 				Int("index", i),
 				Float64("width", img.width),
@@ -128,42 +159,45 @@ func ConvertImagesToPDF(dir, output, password string) error {
 
 		// 设置基础加密
 		if password != "" {
-			LogInfo("设置PDF密码保护")
+			logger.Info("设置PDF密码保护")
 			pdf.SetProtection(gofpdf.CnProtectPrint, password, password)
 		}
 
 		// 生成PDF
-		LogInfo("正在生成PDF文件", Str("output", output))
+		logger.Info("正在生成PDF文件", Str("output", output))
 		if err := pdf.OutputFileAndClose(output); err != nil {
-			LogError("PDF生成失败", Err(err))
-			return fmt.Errorf("合成 PDF 失败: %s", err)
+			logger.Error("PDF生成失败", Err(err))
+			return Errorf("合成 PDF 失败: %s", err)
 		}
 
-		LogInfo("PDF生成成功",
+		logger.Info("PDF生成成功",
 			Str("output", output),
-			Int("图片数量", len(adjustedImages)),
-			Float64("文件大小(MB)", getFileSizeMB(output)),
+			Int("images", len(adjustedImages)),
+			Float64("size_mb", getFileSizeMB(output)),
 		)
 		return nil
 	} else {
 		// 多层目录处理
-		LogDebug("处理多层目录结构", Int("章节数", len(files)))
+		logger.Debug("处理多层目录结构", Int("chapters", len(files)))
 		pdf := gofpdf.NewCustom(&gofpdf.InitType{
 			UnitStr: "pt",
 		})
 
 		for chapterIdx, chapter := range files {
-			LogDebug("处理章节",
+			logger.Debug("处理章节",
 				Int("chapter", chapterIdx+1),
 				Str("name", chapter.Name),
-				Int("图片数量", len(chapter.Files)),
+				Int("images", len(chapter.Files)),
 			)
 
 			imageInfos := make([]imageInfo, 0, len(chapter.Files))
 			for _, file := range chapter.Files {
-				imgInfo := pdf.RegisterImage(file, "")
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				imgInfo := ev.register(pdf, file)
 				if imgInfo == nil {
-					LogWarn("图片注册失败，跳过", Str("file", file))
+					logger.Warn("图片注册失败，跳过", Str("file", file))
 					continue
 				}
 				imageInfos = append(imageInfos, imageInfo{
@@ -174,7 +208,7 @@ func ConvertImagesToPDF(dir, output, password string) error {
 			}
 
 			if len(imageInfos) == 0 {
-				LogWarn("章节中没有有效图片，跳过",
+				logger.Warn("章节中没有有效图片，跳过",
 					Int("chapter", chapterIdx+1),
 					Str("name", chapter.Name),
 				)
@@ -194,7 +228,7 @@ func ConvertImagesToPDF(dir, output, password string) error {
 				finalHeight += img.height
 			}
 
-			LogDebug("章节页面尺寸计算完成",
+			logger.Debug("章节页面尺寸计算完成",
 				Int("chapter", chapterIdx+1),
 				Float64("width", finalWidth),
 				Float64("height", finalHeight),
@@ -212,7 +246,7 @@ func ConvertImagesToPDF(dir, output, password string) error {
 			// 添加图片
 			var currentY float64
 			for pageIdx, img := range adjustedImages {
-				LogDebug("添加图片到章节",
+				logger.Debug("添加图片到章节",
 					Int("chapter", chapterIdx+1),
 					Int("page", pageIdx+1),
 					Str("file", img.path),
@@ -237,21 +271,21 @@ func ConvertImagesToPDF(dir, output, password string) error {
 		}
 		// 设置基础加密
 		if password != "" {
-			LogInfo("设置PDF密码保护")
+			logger.Info("设置PDF密码保护")
 			pdf.SetProtection(gofpdf.CnProtectPrint, password, password)
 		}
 		// 生成PDF
-		LogInfo("正在生成多章节PDF文件", Str("output", output))
+		logger.Info("正在生成多章节PDF文件", Str("output", output))
 		if err := pdf.OutputFileAndClose(output); err != nil {
-			LogError("PDF生成失败", Err(err))
-			return fmt.Errorf("合成 PDF 失败: %s", err)
+			logger.Error("PDF生成失败", Err(err))
+			return Errorf("合成 PDF 失败: %s", err)
 		}
 
-		LogInfo("多章节PDF生成成功",
+		logger.Info("多章节PDF生成成功",
 			Str("output", output),
-			Int("章节数", len(files)),
-			Int("总图片数", totalFileCount(files)),
-			Float64("文件大小(MB)", getFileSizeMB(output)),
+			Int("chapters", len(files)),
+			Int("total_images", totalFileCount(files)),
+			Float64("size_mb", getFileSizeMB(output)),
 		)
 		return nil
 	}
@@ -259,11 +293,48 @@ func ConvertImagesToPDF(dir, output, password string) error {
 
 // 辅助函数 =========================================
 
+// pdfEvents 合成 PDF 时发送单项事件
+type pdfEvents struct {
+	handler   EventHandler
+	total     int
+	index     int
+	succeeded int
+	failed    int
+}
+
+// register 注册图片并发送事件，失败时清除 PDF 的错误状态，以便跳过该图片继续合成
+func (e *pdfEvents) register(pdf *gofpdf.Fpdf, file string) *gofpdf.ImageInfoType {
+	start := time.Now()
+	emit(e.handler, Event{Type: EventItemStarted, Stage: StagePdf, Index: e.index, Total: e.total, Input: file})
+
+	// 解析失败时 gofpdf 可能仍然返回图片信息，需要以 pdf.Error() 为准
+	info := pdf.RegisterImage(file, "")
+	err := pdf.Error()
+	if info == nil && err == nil {
+		err = Errorf("无法读取图片")
+	}
+	if err != nil {
+		info = nil
+		if !errors.Is(err, fs.ErrNotExist) {
+			err = fmt.Errorf("%w: %w", ErrCorruptImage, err)
+		}
+		err = &ImageError{Path: file, Err: err}
+		pdf.ClearError()
+		e.failed++
+	} else {
+		e.succeeded++
+	}
+
+	emit(e.handler, resultEvent(StagePdf, e.index, e.total, file, "", err, 0, time.Since(start)))
+	e.index++
+	return info
+}
+
 // 确保输出目录存在
-func ensureOutputDir(output string) error {
+func ensureOutputDir(ctx context.Context, output string) error {
 	dir := filepath.Dir(output)
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		LogDebug("创建输出目录", Str("path", dir))
+		LoggerFrom(ctx).Debug("创建输出目录", Str("path", dir))
 		return os.MkdirAll(dir, 0755)
 	}
 	return nil
@@ -0,0 +1,278 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+/*
+插件协议（stdin/stdout JSON）
+
+pickit 每次调用插件都会启动一次插件进程，向其 stdin 写入一个 JSON 请求，
+然后从 stdout 读取一个 JSON 响应，插件处理完毕后应当退出。stderr 的内容会被记录到日志中。
+
+请求的公共字段:
+
+	{"version": 1, "method": "<方法名>", ...}
+
+支持的方法:
+
+	describe  返回插件信息
+	          请求: {"version":1,"method":"describe"}
+	          响应: {"name":"xxx","capabilities":["segments","urls"]}
+
+	segments  替代 GetNum，返回每张图片被切割的刀数
+	          请求: {"version":1,"method":"segments","aid":123,"scramble_id":220980,"filenames":["00001","00002"]}
+	          响应: {"segments":{"00001":10,"00002":0}}
+
+	urls      替代 ImageUrlBuilder，返回需要下载的图片地址
+	          请求: {"version":1,"method":"urls","aid":123,"cdn":"https://cdn","count":20}
+	          响应: {"urls":["https://cdn/...","..."]}
+
+任何方法出错时都可以返回 {"error":"错误信息"}，或者以非 0 状态码退出。
+*/
+
+// PluginProtocolVersion 插件协议版本
+const PluginProtocolVersion = 1
+
+// 插件能力
+const (
+	PluginCapSegments = "segments"
+	PluginCapUrls     = "urls"
+)
+
+// pluginTimeout 单次插件调用的超时时间
+const pluginTimeout = 30 * time.Second
+
+type Plugin struct {
+	Name         string
+	Path         string
+	Capabilities []string
+}
+
+type pluginRequest struct {
+	Version    int      `json:"version"`
+	Method     string   `json:"method"`
+	Aid        int      `json:"aid,omitempty"`
+	ScrambleId int      `json:"scramble_id,omitempty"`
+	Filenames  []string `json:"filenames,omitempty"`
+	Cdn        string   `json:"cdn,omitempty"`
+	Count      int      `json:"count,omitempty"`
+}
+
+type pluginResponse struct {
+	Error        string         `json:"error,omitempty"`
+	Name         string         `json:"name,omitempty"`
+	Capabilities []string       `json:"capabilities,omitempty"`
+	Segments     map[string]int `json:"segments,omitempty"`
+	Urls         []string       `json:"urls,omitempty"`
+}
+
+// DefaultPluginDir 默认插件目录: <用户配置目录>/pickit/plugins
+func DefaultPluginDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return filepath.Join(".", "plugins")
+	}
+	return filepath.Join(dir, "pickit", "plugins")
+}
+
+// DiscoverPlugins 扫描插件目录中的可执行文件
+func DiscoverPlugins(dir string) ([]Plugin, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Plugin{}, nil
+		}
+		return nil, Errorf("读取插件目录失败: %w", err)
+	}
+
+	plugins := make([]Plugin, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !isExecutable(entry.Name(), info.Mode()) {
+			continue
+		}
+		plugins = append(plugins, Plugin{
+			Name: pluginName(entry.Name()),
+			Path: filepath.Join(dir, entry.Name()),
+		})
+	}
+
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Name < plugins[j].Name
+	})
+	return plugins, nil
+}
+
+// LoadPlugin 按名称加载插件，并通过 describe 获取其能力
+func LoadPlugin(ctx context.Context, dir, name string) (*Plugin, error) {
+	plugins, err := DiscoverPlugins(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range plugins {
+		if p.Name != name {
+			continue
+		}
+		plugin := p
+		if err := plugin.Describe(ctx); err != nil {
+			return nil, err
+		}
+		LoggerFrom(ctx).Info("插件加载完成",
+			Str("plugin", plugin.Name),
+			Str("path", plugin.Path),
+			Strings("capabilities", plugin.Capabilities))
+		return &plugin, nil
+	}
+
+	return nil, Errorf("插件 %s 不存在于目录 %s", name, dir)
+}
+
+// Describe 获取插件名称和能力
+func (p *Plugin) Describe(ctx context.Context) error {
+	resp, err := p.call(ctx, pluginRequest{Method: "describe"})
+	if err != nil {
+		return err
+	}
+	if resp.Name != "" {
+		p.Name = resp.Name
+	}
+	p.Capabilities = resp.Capabilities
+	return nil
+}
+
+// Supports 判断插件是否具备某项能力
+func (p *Plugin) Supports(capability string) bool {
+	for _, c := range p.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// Segments 通过插件获取每张图片的切割刀数，返回值以文件名（不带后缀）为键
+func (p *Plugin) Segments(ctx context.Context, scrambleId, aid int, filenames []string) (map[string]int, error) {
+	if !p.Supports(PluginCapSegments) {
+		return nil, Errorf("插件 %s 不支持 %s", p.Name, PluginCapSegments)
+	}
+
+	resp, err := p.call(ctx, pluginRequest{
+		Method:     PluginCapSegments,
+		Aid:        aid,
+		ScrambleId: scrambleId,
+		Filenames:  filenames,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range filenames {
+		num, ok := resp.Segments[name]
+		if !ok {
+			return nil, Errorf("插件 %s 没有返回文件 %s 的切割方案", p.Name, name)
+		}
+		if num < 0 {
+			return nil, Errorf("插件 %s 返回了无效的刀数: %s=%d", p.Name, name, num)
+		}
+	}
+	return resp.Segments, nil
+}
+
+// Urls 通过插件获取图片下载地址
+func (p *Plugin) Urls(ctx context.Context, aid int, cdn string, count int) ([]string, error) {
+	if !p.Supports(PluginCapUrls) {
+		return nil, Errorf("插件 %s 不支持 %s", p.Name, PluginCapUrls)
+	}
+
+	resp, err := p.call(ctx, pluginRequest{
+		Method: PluginCapUrls,
+		Aid:    aid,
+		Cdn:    cdn,
+		Count:  count,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Urls) == 0 {
+		return nil, Errorf("插件 %s 没有返回任何下载地址", p.Name)
+	}
+	return resp.Urls, nil
+}
+
+// call 启动插件进程并完成一次请求/响应，ctx 取消或超时后结束插件进程
+func (p *Plugin) call(ctx context.Context, req pluginRequest) (*pluginResponse, error) {
+	req.Version = PluginProtocolVersion
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, Errorf("序列化插件请求失败: %w", err)
+	}
+
+	logger := LoggerFrom(ctx)
+	ctx, cancel := context.WithTimeout(ctx, pluginTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.Path)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	logger.Debug("调用插件",
+		Str("plugin", p.Name),
+		Str("method", req.Method))
+	err = cmd.Run()
+	if stderr.Len() > 0 {
+		logger.Debug("插件输出",
+			Str("plugin", p.Name),
+			Str("stderr", strings.TrimSpace(stderr.String())))
+	}
+	if err != nil {
+		return nil, Errorf("插件 %s 执行失败: %w", p.Name, err)
+	}
+
+	var resp pluginResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, Errorf("插件 %s 响应解析失败: %w", p.Name, err)
+	}
+	if resp.Error != "" {
+		return nil, Errorf("插件 %s 返回错误: %s", p.Name, resp.Error)
+	}
+	return &resp, nil
+}
+
+// isExecutable 判断文件是否可执行
+func isExecutable(name string, mode os.FileMode) bool {
+	if !mode.IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".exe", ".bat", ".cmd":
+			return true
+		}
+		return false
+	}
+	return mode&0111 != 0
+}
+
+// pluginName 插件名为去掉扩展名的文件名
+func pluginName(filename string) string {
+	if runtime.GOOS == "windows" {
+		return strings.TrimSuffix(filename, filepath.Ext(filename))
+	}
+	return filename
+}
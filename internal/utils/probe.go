@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ProbeResult 探测下载任务的结果
+type ProbeResult struct {
+	Url      string        // 实际会下载的地址，原地址 404 时为可用的备选地址
+	Dist     string        // 对应的保存路径
+	Size     int64         // 文件大小，服务端没有返回时为 -1
+	Err      error         // 所有地址都不可用时的错误
+	Duration time.Duration // 探测耗时
+}
+
+// Probe 发送 HEAD 请求获取文件大小，不下载文件内容。服务端不支持 HEAD 或没有返回大小时 size 为 -1
+func (d *Downloader) Probe(ctx context.Context, url string) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return -1, Errorf("创建HTTP请求失败: %w (url=%s)", err, url)
+	}
+	resp, err := d.httpClient().Do(req)
+	if err != nil {
+		return -1, Errorf("HTTP请求失败: %w (url=%s)", err, url)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.ContentLength, nil
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return -1, nil
+	}
+	return -1, newHTTPError(url, resp)
+}
+
+// probeWithFallback 探测下载任务，404 时依次探测备选地址，与 downloadWithFallback 的选择顺序一致
+func (d *Downloader) probeWithFallback(ctx context.Context, task DownloadTask) ProbeResult {
+	start := time.Now()
+	var err error
+	for _, candidate := range append([]DownloadTask{task}, task.Fallbacks...) {
+		var size int64
+		size, err = d.Probe(ctx, candidate.Url)
+		if err == nil {
+			return ProbeResult{Url: candidate.Url, Dist: candidate.Dist, Size: size, Duration: time.Since(start)}
+		}
+		if !errors.Is(err, ErrNotFound) {
+			break
+		}
+	}
+	return ProbeResult{Url: task.Url, Dist: task.Dist, Size: -1, Err: err, Duration: time.Since(start)}
+}
+
+// BatchProbe 多线程探测下载任务，结果按任务顺序返回
+func (d *Downloader) BatchProbe(ctx context.Context, tasks []DownloadTask, workers int) []ProbeResult {
+	results := make([]ProbeResult, len(tasks))
+	if workers > len(tasks) {
+		workers = len(tasks)
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+
+	taskCh := make(chan int, len(tasks))
+	for i := range tasks {
+		taskCh <- i
+	}
+	close(taskCh)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range taskCh {
+				task := tasks[idx]
+				if err := ctx.Err(); err != nil {
+					results[idx] = ProbeResult{Url: task.Url, Dist: task.Dist, Size: -1, Err: err}
+					continue
+				}
+				results[idx] = d.probeWithFallback(ctx, task)
+			}
+		}()
+	}
+	wg.Wait()
+
+	LoggerFrom(ctx).Info("探测下载地址完成", Int("total", len(tasks)), Int("workers", workers))
+	return results
+}
+
+// MeasureThroughput 下载 url 并丢弃内容，返回单个连接的下载速度（字节/秒），用于估算下载耗时
+func (d *Downloader) MeasureThroughput(ctx context.Context, url string) (float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, Errorf("创建HTTP请求失败: %w (url=%s)", err, url)
+	}
+	start := time.Now()
+	resp, err := d.httpClient().Do(req)
+	if err != nil {
+		return 0, Errorf("HTTP请求失败: %w (url=%s)", err, url)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, newHTTPError(url, resp)
+	}
+
+	n, err := io.Copy(io.Discard, resp.Body)
+	if err != nil {
+		return 0, Errorf("文件复制失败: %w", err)
+	}
+	elapsed := time.Since(start).Seconds()
+	if n == 0 || elapsed <= 0 {
+		return 0, nil
+	}
+	return float64(n) / elapsed, nil
+}
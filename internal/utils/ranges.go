@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// IntRange 闭区间，End 为 0 表示没有上限
+type IntRange struct {
+	Start int
+	End   int
+}
+
+// IntRanges 由逗号分隔的编号区间，如 "3-7,9,12-"
+type IntRanges []IntRange
+
+// ParseIntRanges 解析编号区间，编号从 1 开始
+func ParseIntRanges(s string) (IntRanges, error) {
+	ranges := make(IntRanges, 0)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		var r IntRange
+		var err error
+		if before, after, found := strings.Cut(item, "-"); found {
+			r.Start, err = parseRangeBound(before, 1)
+			if err != nil {
+				return nil, Errorf("无效的区间 %q: %w", item, err)
+			}
+			r.End, err = parseRangeBound(after, 0)
+			if err != nil {
+				return nil, Errorf("无效的区间 %q: %w", item, err)
+			}
+			if r.End != 0 && r.End < r.Start {
+				return nil, Errorf("无效的区间 %q: 结束编号小于起始编号", item)
+			}
+		} else {
+			r.Start, err = parseRangeBound(item, 0)
+			if err != nil || r.Start == 0 {
+				return nil, Errorf("无效的编号 %q", item)
+			}
+			r.End = r.Start
+		}
+		ranges = append(ranges, r)
+	}
+
+	if len(ranges) == 0 {
+		return nil, Errorf("区间不能为空")
+	}
+	return ranges, nil
+}
+
+// Contains 判断编号是否在区间内
+func (rs IntRanges) Contains(n int) bool {
+	for _, r := range rs {
+		if n >= r.Start && (r.End == 0 || n <= r.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRangeBound 解析区间端点，为空时返回默认值
+func parseRangeBound(s string, def int) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return 0, Errorf("编号必须是正整数")
+	}
+	return n, nil
+}
@@ -0,0 +1,180 @@
+package utils
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/*
+PageSelection 页面选择表达式。
+多个条目用逗号分隔，页码从 1 开始:
+
+	5        第 5 页
+	-1       倒数第 1 页
+	1-20     第 1 到 20 页
+	10-      第 10 页到最后一页
+	3--2     第 3 页到倒数第 2 页
+	ch2:1-5  只作用于第 2 章的第 1 到 5 页，不带 chN: 前缀的条目作用于所有章节
+
+包含列表为空时表示选择全部页面，排除列表优先于包含列表。
+*/
+type PageSelection struct {
+	include []pageSelector
+	exclude []pageSelector
+}
+
+type pageSelector struct {
+	chapter int // 0 表示所有章节
+	start   int // 负数表示倒数
+	end     int // 负数表示倒数，0 表示到最后一页
+}
+
+var pageSelectorPattern = regexp.MustCompile(`^(?:ch(\d+):)?(-?\d+)(-(-?\d+)?)?$`)
+
+// ParsePageSelection 解析包含和排除表达式，两者都为空时返回 nil
+func ParsePageSelection(include, exclude string) (*PageSelection, error) {
+	if strings.TrimSpace(include) == "" && strings.TrimSpace(exclude) == "" {
+		return nil, nil
+	}
+
+	var err error
+	sel := &PageSelection{}
+	if sel.include, err = parsePageSelectors(include); err != nil {
+		return nil, err
+	}
+	if sel.exclude, err = parsePageSelectors(exclude); err != nil {
+		return nil, err
+	}
+	return sel, nil
+}
+
+func parsePageSelectors(expr string) ([]pageSelector, error) {
+	selectors := make([]pageSelector, 0)
+	for _, item := range strings.Split(expr, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+
+		m := pageSelectorPattern.FindStringSubmatch(item)
+		if m == nil {
+			return nil, Errorf("无效的页面选择 %q，格式如 1-20,25,-3,ch2:1-5", item)
+		}
+
+		var s pageSelector
+		if m[1] != "" {
+			s.chapter, _ = strconv.Atoi(m[1])
+			if s.chapter == 0 {
+				return nil, Errorf("无效的页面选择 %q: 章节从 1 开始", item)
+			}
+		}
+		s.start, _ = strconv.Atoi(m[2])
+		if s.start == 0 {
+			return nil, Errorf("无效的页面选择 %q: 页码从 1 开始", item)
+		}
+
+		switch {
+		case m[3] == "":
+			s.end = s.start
+		case m[4] == "":
+			if s.start < 0 {
+				return nil, Errorf("无效的页面选择 %q: 倒数页码不能作为开放区间的起点", item)
+			}
+			s.end = 0
+		default:
+			s.end, _ = strconv.Atoi(m[4])
+			if s.end == 0 {
+				return nil, Errorf("无效的页面选择 %q: 页码从 1 开始", item)
+			}
+		}
+		selectors = append(selectors, s)
+	}
+	return selectors, nil
+}
+
+// Contains 判断第 chapter 章（从 1 开始）共 total 页中的第 page 页（从 1 开始）是否被选中
+func (sel *PageSelection) Contains(chapter, page, total int) bool {
+	if sel == nil {
+		return true
+	}
+	for _, s := range sel.exclude {
+		if s.matches(chapter, page, total) {
+			return false
+		}
+	}
+	if len(sel.include) == 0 {
+		return true
+	}
+	for _, s := range sel.include {
+		if s.matches(chapter, page, total) {
+			return true
+		}
+	}
+	return false
+}
+
+// Pages 返回第 chapter 章中被选中的页码（从 1 开始）
+func (sel *PageSelection) Pages(chapter, total int) []int {
+	pages := make([]int, 0, total)
+	for page := 1; page <= total; page++ {
+		if sel.Contains(chapter, page, total) {
+			pages = append(pages, page)
+		}
+	}
+	return pages
+}
+
+func (s pageSelector) matches(chapter, page, total int) bool {
+	if s.chapter != 0 && s.chapter != chapter {
+		return false
+	}
+	start := resolvePageIndex(s.start, total)
+	end := total
+	if s.end != 0 {
+		end = resolvePageIndex(s.end, total)
+	}
+	return page >= start && page <= end
+}
+
+// resolvePageIndex 将倒数页码转换为正数页码
+func resolvePageIndex(n, total int) int {
+	if n < 0 {
+		return total + n + 1
+	}
+	return n
+}
+
+// ChapterNumber 章节序号：目录名为数字时使用目录名，否则使用其在列表中的位置（从 1 开始）
+func ChapterNumber(info DirInfo, idx int) int {
+	if n, err := strconv.Atoi(info.Name); err == nil && n > 0 {
+		return n
+	}
+	return idx + 1
+}
+
+// FilterDirInfo 按页面选择过滤目录信息，过滤后没有文件的章节会被移除
+func FilterDirInfo(infos []DirInfo, sel *PageSelection) []DirInfo {
+	if sel == nil {
+		return infos
+	}
+
+	result := make([]DirInfo, 0, len(infos))
+	for idx, info := range infos {
+		chapter := ChapterNumber(info, idx)
+		files := make([]string, 0, len(info.Files))
+		for _, page := range sel.Pages(chapter, len(info.Files)) {
+			files = append(files, info.Files[page-1])
+		}
+
+		LogInfo("页面选择完成",
+			Int("chapter", chapter),
+			Int("total", len(info.Files)),
+			Int("selected", len(files)))
+		if len(files) == 0 {
+			continue
+		}
+		result = append(result, DirInfo{Name: info.Name, Files: files})
+	}
+	return result
+}
@@ -0,0 +1,241 @@
+package utils
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrSessionExpired 本地保存的登录状态已过期
+	ErrSessionExpired = NewError("登录已过期，请执行 pickit login 重新登录")
+	// ErrUnauthorized 服务端拒绝了请求（401/403），通常是没有登录或登录已失效
+	ErrUnauthorized = NewError("需要登录或登录已失效，请执行 pickit login 重新登录")
+)
+
+// Session 登录状态，包括 cookie 和 token
+type Session struct {
+	Url       string          `json:"url"`                  // 登录时使用的接口地址
+	Username  string          `json:"username"`             // 用户名
+	Token     string          `json:"token,omitempty"`      // 接口返回的 token
+	ExpiresAt time.Time       `json:"expires_at,omitempty"` // 过期时间，零值表示未知
+	Cookies   []SessionCookie `json:"cookies"`
+}
+
+type SessionCookie struct {
+	Name    string    `json:"name"`
+	Value   string    `json:"value"`
+	Domain  string    `json:"domain,omitempty"`
+	Path    string    `json:"path,omitempty"`
+	Expires time.Time `json:"expires,omitempty"`
+}
+
+// DefaultSessionPath 默认的登录状态文件: <用户配置目录>/pickit/session.json
+func DefaultSessionPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return filepath.Join(".", "session.json")
+	}
+	return filepath.Join(dir, "pickit", "session.json")
+}
+
+// Expired 判断登录状态是否已过期
+func (s *Session) Expired() bool {
+	return !s.ExpiresAt.IsZero() && time.Now().After(s.ExpiresAt)
+}
+
+// LoadSession 读取登录状态，文件不存在时返回 nil, nil
+func LoadSession(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, Errorf("读取登录状态失败: %w", err)
+	}
+
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, Errorf("解析登录状态失败: %w", err)
+	}
+	if s.Expired() {
+		return &s, ErrSessionExpired
+	}
+	return &s, nil
+}
+
+// SaveSession 保存登录状态，文件权限为 0600
+func SaveSession(path string, s *Session) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return Errorf("创建配置目录失败: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return Errorf("序列化登录状态失败: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return Errorf("保存登录状态失败: %w", err)
+	}
+	return nil
+}
+
+// RemoveSession 删除登录状态
+func RemoveSession(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return Errorf("删除登录状态失败: %w", err)
+	}
+	return nil
+}
+
+// sessionTransport 为发往登录接口所在主机的请求附加 token
+type sessionTransport struct {
+	base    http.RoundTripper
+	host    string
+	token   string
+	expires time.Time
+}
+
+func (t *sessionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.expires.IsZero() && time.Now().After(t.expires) {
+		return nil, ErrSessionExpired
+	}
+	if t.token != "" && req.URL.Host == t.host && req.Header.Get("Authorization") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// Apply 让客户端携带登录状态：替换客户端的 cookie jar，并为发往登录接口所在主机的请求附加 token。
+// 登录状态过期后客户端的请求直接返回 ErrSessionExpired
+func (s *Session) Apply(client *http.Client) error {
+	u, err := url.Parse(s.Url)
+	if err != nil {
+		return Errorf("登录状态中的地址无效: %w", err)
+	}
+	jar, _ := cookiejar.New(nil)
+	cookies := make([]*http.Cookie, 0, len(s.Cookies))
+	for _, c := range s.Cookies {
+		cookies = append(cookies, &http.Cookie{
+			Name:    c.Name,
+			Value:   c.Value,
+			Domain:  c.Domain,
+			Path:    c.Path,
+			Expires: c.Expires,
+		})
+	}
+	jar.SetCookies(u, cookies)
+
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client.Jar = jar
+	client.Transport = &sessionTransport{
+		base:    base,
+		host:    u.Host,
+		token:   s.Token,
+		expires: s.ExpiresAt,
+	}
+	return nil
+}
+
+type loginResponse struct {
+	Token     string `json:"token"`
+	ExpiresIn int64  `json:"expires_in"` // 秒
+	Error     string `json:"error"`
+}
+
+/*
+Login 登录并返回登录状态。
+
+	POST {api}/login  (application/x-www-form-urlencoded: username, password)
+	200 {"token": "可选", "expires_in": 86400}，并通过 Set-Cookie 下发 cookie
+	其他状态码 {"error": "错误信息"}
+*/
+func Login(apiBase, proxy, username, password string) (*Session, error) {
+	apiBase = strings.TrimSuffix(apiBase, "/")
+	if apiBase == "" {
+		return nil, Errorf("未配置接口地址")
+	}
+	u, err := url.Parse(apiBase)
+	if err != nil {
+		return nil, Errorf("接口地址无效: %w", err)
+	}
+
+	client := NewHTTPClient(proxy, 15*time.Second)
+	jar, _ := cookiejar.New(nil)
+	client.Jar = jar
+
+	LogInfo("正在登录", Str("api", apiBase), Str("username", username))
+	resp, err := client.PostForm(apiBase+"/login", url.Values{
+		"username": {username},
+		"password": {password},
+	})
+	if err != nil {
+		return nil, Errorf("登录请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, Errorf("读取登录响应失败: %w", err)
+	}
+	var lr loginResponse
+	_ = json.Unmarshal(body, &lr)
+
+	if resp.StatusCode != http.StatusOK {
+		if lr.Error != "" {
+			return nil, Errorf("登录失败: %s", lr.Error)
+		}
+		return nil, Errorf("登录失败: 无效状态码 %d", resp.StatusCode)
+	}
+
+	s := &Session{
+		Url:      apiBase,
+		Username: username,
+		Token:    lr.Token,
+	}
+	if lr.ExpiresIn > 0 {
+		s.ExpiresAt = time.Now().Add(time.Duration(lr.ExpiresIn) * time.Second)
+	}
+
+	// 记录 cookie，cookie 的过期时间早于 token 时以 cookie 为准
+	for _, c := range resp.Cookies() {
+		if c.MaxAge > 0 {
+			c.Expires = time.Now().Add(time.Duration(c.MaxAge) * time.Second)
+		}
+		s.Cookies = append(s.Cookies, SessionCookie{
+			Name:    c.Name,
+			Value:   c.Value,
+			Domain:  c.Domain,
+			Path:    c.Path,
+			Expires: c.Expires,
+		})
+		if !c.Expires.IsZero() && (s.ExpiresAt.IsZero() || c.Expires.Before(s.ExpiresAt)) {
+			s.ExpiresAt = c.Expires
+		}
+	}
+	if len(s.Cookies) == 0 {
+		// 通过重定向等方式下发的 cookie
+		for _, c := range jar.Cookies(u) {
+			s.Cookies = append(s.Cookies, SessionCookie{Name: c.Name, Value: c.Value})
+		}
+	}
+
+	if s.Token == "" && len(s.Cookies) == 0 {
+		return nil, Errorf("登录失败: 服务端没有返回 token 或 cookie")
+	}
+
+	LogInfo("登录成功",
+		Str("username", username),
+		Int("cookies", len(s.Cookies)),
+		Bool("token", s.Token != ""))
+	return s, nil
+}
@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// newLoginServer 启动本地的登录接口: /login 校验用户名和密码后下发 cookie 和 token，/me 只接受携带两者的请求
+func newLoginServer(t *testing.T, expiresIn int) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.FormValue("username") != "alice" || r.FormValue("password") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error": "用户名或密码错误"}`))
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: "cookie-value", Path: "/", MaxAge: 3600})
+		_ = json.NewEncoder(w).Encode(map[string]any{"token": "token-value", "expires_in": expiresIn})
+	})
+	mux.HandleFunc("/me", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("sid")
+		if err != nil || cookie.Value != "cookie-value" || r.Header.Get("Authorization") != "Bearer token-value" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("alice"))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestLogin(t *testing.T) {
+	srv := newLoginServer(t, 86400)
+
+	s, err := Login(srv.URL+"/", "", "alice", "secret")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if s.Url != srv.URL || s.Username != "alice" || s.Token != "token-value" {
+		t.Errorf("session = %+v", s)
+	}
+	if len(s.Cookies) != 1 || s.Cookies[0].Name != "sid" || s.Cookies[0].Value != "cookie-value" {
+		t.Errorf("cookies = %+v", s.Cookies)
+	}
+	// cookie 的 Max-Age 早于 token 的过期时间，以 cookie 为准
+	if until := time.Until(s.ExpiresAt); until <= 0 || until > time.Hour {
+		t.Errorf("ExpiresAt = %v, want within an hour", s.ExpiresAt)
+	}
+}
+
+func TestLoginFailed(t *testing.T) {
+	srv := newLoginServer(t, 0)
+
+	_, err := Login(srv.URL, "", "alice", "wrong")
+	if err == nil {
+		t.Fatal("Login with wrong password: want error")
+	}
+	if got := err.Error(); got != "登录失败: 用户名或密码错误" {
+		t.Errorf("err = %q", got)
+	}
+	if _, err := Login("", "", "alice", "secret"); err == nil {
+		t.Error("Login without api: want error")
+	}
+}
+
+func TestSessionPersistence(t *testing.T) {
+	srv := newLoginServer(t, 86400)
+	s, err := Login(srv.URL, "", "alice", "secret")
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "pickit", "session.json")
+	if err := SaveSession(path, s); err != nil {
+		t.Fatalf("SaveSession: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil {
+		t.Fatalf("Stat: %v", err)
+	} else if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("perm = %v, want 0600", info.Mode().Perm())
+	}
+
+	loaded, err := LoadSession(path)
+	if err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+
+	// Apply 让客户端携带 cookie 和 token
+	client := NewHTTPClient("", 5*time.Second)
+	if err := loaded.Apply(client); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	resp, err := client.Get(srv.URL + "/me")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+
+	// 没有应用登录状态的客户端不受影响
+	resp, err = NewHTTPClient("", 5*time.Second).Get(srv.URL + "/me")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status without session = %d, want 401", resp.StatusCode)
+	}
+}
+
+func TestSessionExpired(t *testing.T) {
+	srv := newLoginServer(t, 0)
+	expired := &Session{
+		Url:       srv.URL,
+		Username:  "alice",
+		Token:     "token-value",
+		ExpiresAt: time.Now().Add(-time.Minute),
+		Cookies:   []SessionCookie{{Name: "sid", Value: "cookie-value"}},
+	}
+
+	path := filepath.Join(t.TempDir(), "session.json")
+	if err := SaveSession(path, expired); err != nil {
+		t.Fatalf("SaveSession: %v", err)
+	}
+	loaded, err := LoadSession(path)
+	if !errors.Is(err, ErrSessionExpired) {
+		t.Fatalf("LoadSession err = %v, want ErrSessionExpired", err)
+	}
+	if loaded == nil || loaded.Username != "alice" {
+		t.Errorf("LoadSession should still return the expired session, got %+v", loaded)
+	}
+
+	// 已加载的登录状态在使用过程中过期时，请求直接返回 ErrSessionExpired
+	client := &http.Client{Timeout: 5 * time.Second}
+	if err := expired.Apply(client); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	_, err = client.Get(srv.URL + "/me")
+	if !errors.Is(err, ErrSessionExpired) {
+		t.Errorf("Get err = %v, want ErrSessionExpired", err)
+	}
+}
+
+func TestLoadSessionMissing(t *testing.T) {
+	s, err := LoadSession(filepath.Join(t.TempDir(), "missing.json"))
+	if s != nil || err != nil {
+		t.Errorf("LoadSession = %v, %v, want nil, nil", s, err)
+	}
+}
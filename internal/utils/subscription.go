@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Subscription 订阅的本子及其输出设置
+type Subscription struct {
+	Aid         int       `json:"aid"`
+	Title       string    `json:"title,omitempty"`
+	Output      string    `json:"output"`             // 输出目录
+	Cdn         string    `json:"cdn"`                // 图片域名地址
+	Proxy       string    `json:"proxy,omitempty"`    // 魔法
+	Concurrency int       `json:"concurrency"`        // 并发数
+	Pdf         bool      `json:"pdf"`                // 同步后是否重新合成 PDF
+	Password    string    `json:"password,omitempty"` // PDF 密码
+	Chapters    []int     `json:"chapters"`           // 已同步的章节编号
+	LastSync    time.Time `json:"last_sync,omitempty"`
+}
+
+// HasChapter 判断章节是否已同步
+func (s *Subscription) HasChapter(id int) bool {
+	for _, c := range s.Chapters {
+		if c == id {
+			return true
+		}
+	}
+	return false
+}
+
+// SubscriptionStore 订阅列表，保存为 JSON 文件
+type SubscriptionStore struct {
+	path          string
+	Subscriptions []Subscription `json:"subscriptions"`
+}
+
+// DefaultSubscriptionPath 默认的订阅文件: <用户配置目录>/pickit/subscriptions.json
+func DefaultSubscriptionPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return filepath.Join(".", "subscriptions.json")
+	}
+	return filepath.Join(dir, "pickit", "subscriptions.json")
+}
+
+// LoadSubscriptions 读取订阅列表，文件不存在时返回空列表
+func LoadSubscriptions(path string) (*SubscriptionStore, error) {
+	store := &SubscriptionStore{path: path, Subscriptions: []Subscription{}}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return nil, Errorf("读取订阅列表失败: %w", err)
+	}
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, Errorf("解析订阅列表失败: %w", err)
+	}
+	return store, nil
+}
+
+// Save 保存订阅列表，先写入临时文件再替换，避免写到一半时文件损坏。
+// 订阅中可能保存了 PDF 密码和魔法，文件权限为 0600
+func (s *SubscriptionStore) Save() error {
+	sort.Slice(s.Subscriptions, func(i, j int) bool {
+		return s.Subscriptions[i].Aid < s.Subscriptions[j].Aid
+	})
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return Errorf("序列化订阅列表失败: %w", err)
+	}
+	if err := WriteFileAtomic(s.path, data, 0600); err != nil {
+		return Errorf("保存订阅列表失败: %w", err)
+	}
+	return nil
+}
+
+// Get 按车牌号获取订阅，不存在时返回 nil
+func (s *SubscriptionStore) Get(aid int) *Subscription {
+	for i := range s.Subscriptions {
+		if s.Subscriptions[i].Aid == aid {
+			return &s.Subscriptions[i]
+		}
+	}
+	return nil
+}
+
+// Put 添加或更新订阅
+func (s *SubscriptionStore) Put(sub Subscription) {
+	if existing := s.Get(sub.Aid); existing != nil {
+		*existing = sub
+		return
+	}
+	s.Subscriptions = append(s.Subscriptions, sub)
+}
+
+// Remove 删除订阅，返回是否存在
+func (s *SubscriptionStore) Remove(aid int) bool {
+	for i := range s.Subscriptions {
+		if s.Subscriptions[i].Aid == aid {
+			s.Subscriptions = append(s.Subscriptions[:i], s.Subscriptions[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// WriteFileAtomic 先写入同目录下的临时文件，同步到磁盘后再重命名为目标文件
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) // 重命名成功后删除会失败，可以忽略
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmpName, path)
+}
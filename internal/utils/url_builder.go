@@ -1,11 +1,158 @@
 package utils
 
-import "fmt"
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
 
-func ImageUrlBuilder(aid int, cdn string, count int) []string {
+// DefaultUrlTemplate 默认的图片地址模板
+const DefaultUrlTemplate = "{cdn}/media/photos/{aid}/{page:05}.{ext}"
+
+// DefaultImageExt 默认的图片扩展名
+const DefaultImageExt = "webp"
+
+// DefaultFallbackExts 图片 404 时默认依次尝试的扩展名
+var DefaultFallbackExts = []string{"jpg", "png"}
+
+/*
+UrlTemplate 图片地址模板。
+支持的占位符:
+
+	{cdn}   图片域名
+	{aid}   车牌号
+	{page}  页码，从 1 开始；可以带偏移和补零宽度，如 {page-1} 从 0 开始、{page:05} 补零到 5 位
+	{ext}   扩展名（不带点）
+*/
+type UrlTemplate struct {
+	raw   string
+	parts []templatePart
+}
+
+type templatePart struct {
+	literal string // 非占位符时的原样文本
+	name    string // 占位符名称
+	offset  int    // 偏移量
+	width   int    // 补零宽度
+}
+
+var placeholderPattern = regexp.MustCompile(`\{([a-z]+)([+-]\d+)?(?::(\d+))?\}`)
+
+// ParseUrlTemplate 解析图片地址模板
+func ParseUrlTemplate(raw string) (*UrlTemplate, error) {
+	tpl := &UrlTemplate{raw: raw}
+	hasPage := false
+
+	last := 0
+	for _, m := range placeholderPattern.FindAllStringSubmatchIndex(raw, -1) {
+		if m[0] > last {
+			tpl.parts = append(tpl.parts, templatePart{literal: raw[last:m[0]]})
+		}
+		last = m[1]
+
+		part := templatePart{name: raw[m[2]:m[3]]}
+		switch part.name {
+		case "cdn", "ext":
+			if m[4] >= 0 || m[6] >= 0 {
+				return nil, Errorf("占位符 {%s} 不支持偏移和补零: %s", part.name, raw)
+			}
+		case "aid", "page":
+			if m[4] >= 0 {
+				part.offset, _ = strconv.Atoi(raw[m[4]:m[5]])
+			}
+			if m[6] >= 0 {
+				part.width, _ = strconv.Atoi(raw[m[6]:m[7]])
+			}
+			if part.name == "page" {
+				hasPage = true
+			}
+		default:
+			return nil, Errorf("未知的占位符 {%s}: %s", part.name, raw)
+		}
+		tpl.parts = append(tpl.parts, part)
+	}
+	if last < len(raw) {
+		tpl.parts = append(tpl.parts, templatePart{literal: raw[last:]})
+	}
+
+	if !hasPage {
+		return nil, Errorf("图片地址模板缺少 {page} 占位符: %s", raw)
+	}
+	return tpl, nil
+}
+
+// String 返回模板原文
+func (t *UrlTemplate) String() string {
+	return t.raw
+}
+
+// Render 渲染指定页的图片地址，page 从 1 开始
+func (t *UrlTemplate) Render(cdn string, aid, page int, ext string) string {
+	var sb strings.Builder
+	for _, part := range t.parts {
+		switch part.name {
+		case "":
+			sb.WriteString(part.literal)
+		case "cdn":
+			sb.WriteString(strings.TrimSuffix(cdn, "/"))
+		case "ext":
+			sb.WriteString(strings.TrimPrefix(ext, "."))
+		case "aid":
+			sb.WriteString(fmt.Sprintf("%0*d", part.width, aid+part.offset))
+		case "page":
+			sb.WriteString(fmt.Sprintf("%0*d", part.width, page+part.offset))
+		}
+	}
+	return sb.String()
+}
+
+// PageName 按模板中第一个 {page} 的偏移和补零渲染页码，用作保存的文件名（不带扩展名），如 {page:05} 的第 1 页为 00001
+func (t *UrlTemplate) PageName(page int) string {
+	for _, part := range t.parts {
+		if part.name == "page" {
+			return fmt.Sprintf("%0*d", part.width, page+part.offset)
+		}
+	}
+	return strconv.Itoa(page)
+}
+
+// Filename 返回第 page 页保存的文件名，由渲染后的页码和扩展名组成，与地址的最后一段是否包含页码无关
+func (t *UrlTemplate) Filename(page int, ext string) string {
+	return t.PageName(page) + "." + strings.TrimPrefix(ext, ".")
+}
+
+// Build 构建 count 张图片的下载地址
+func (t *UrlTemplate) Build(cdn string, aid, count int, ext string) []string {
 	urls := make([]string, count)
 	for i := 0; i < count; i++ {
-		urls[i] = fmt.Sprintf("%s/media/photos/%d/%05d.webp", cdn, aid, i+1)
+		urls[i] = t.Render(cdn, aid, i+1, ext)
 	}
 	return urls
 }
+
+// FilenameFromUrl 取地址路径的最后一段作为文件名（忽略查询参数）
+func FilenameFromUrl(rawUrl string) string {
+	u, err := url.Parse(rawUrl)
+	if err != nil || u.Path == "" {
+		return path.Base(rawUrl)
+	}
+	return path.Base(u.Path)
+}
+
+// ReplaceUrlExt 替换地址路径中的扩展名（保留查询参数）
+func ReplaceUrlExt(rawUrl, ext string) string {
+	u, err := url.Parse(rawUrl)
+	if err != nil || u.Path == "" {
+		return strings.TrimSuffix(rawUrl, path.Ext(rawUrl)) + "." + strings.TrimPrefix(ext, ".")
+	}
+	u.Path = strings.TrimSuffix(u.Path, path.Ext(u.Path)) + "." + strings.TrimPrefix(ext, ".")
+	return u.String()
+}
+
+func ImageUrlBuilder(aid int, cdn string, count int) []string {
+	tpl, _ := ParseUrlTemplate(DefaultUrlTemplate)
+	return tpl.Build(cdn, aid, count, DefaultImageExt)
+}
@@ -0,0 +1,105 @@
+package pickit
+
+import (
+	"context"
+	"pickit/internal/mode"
+	"pickit/internal/utils"
+)
+
+// DownloadOptions 下载选项，除 Album、Cdn 和 Output 外都可以为零值
+type DownloadOptions struct {
+	Album        AlbumRef     // 本子或章节
+	Cdn          string       // 图片域名地址
+	Output       string       // 保存文件夹路径
+	Count        int          // 图片数量，为 0 时通过本子信息接口获取
+	Concurrency  int          // 并发数，为 0 时使用 8
+	UrlTemplate  string       // 图片地址模板，为空时使用默认模板
+	Ext          string       // 图片扩展名，为空时使用 webp
+	FallbackExts []string     // 图片 404 时依次尝试的备选扩展名，为 nil 时使用 jpg、png，传空切片关闭
+	Chapters     string       // 需要下载的章节范围，如 3-7,9,12-，需要本子信息接口
+	Pages        string       // 需要下载的页面，如 1-20,25,-3,ch2:1-5
+	Exclude      string       // 需要跳过的页面，格式同 Pages
+	AlbumDir     string       // 本子目录模板，为空时直接保存到输出目录
+	ChapterDir   string       // 多章节本子的章节目录模板，为空时使用 {chapter}
+	Plugin       string       // 提供下载地址的插件名
+	Events       EventHandler // 进度事件，可以为 nil
+	Force        bool         // 重新下载清单中已完成的页面，默认跳过
+}
+
+// Download 下载本子或章节，返回每一页的下载结果，结果按页面顺序排列。
+// 参数无效或无法确定需要下载的页面时返回错误，单页下载失败记录在对应结果的 Err 中
+func (c *Client) Download(ctx context.Context, opts DownloadOptions) ([]PageResult, error) {
+	ctx = c.context(ctx)
+	options, chapters, err := c.downloadOptions(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return mode.DownloadRef(ctx, options, c.metadataProvider(), opts.Album, chapters)
+}
+
+// PlanDownload 生成下载计划：确定需要下载的页面和保存路径，检查会被覆盖的文件，
+// 并通过 HEAD 请求估算下载总大小和按 Concurrency 并发下载的耗时，不写入任何文件
+func (c *Client) PlanDownload(ctx context.Context, opts DownloadOptions) (*Plan, error) {
+	ctx = c.context(ctx)
+	options, chapters, err := c.downloadOptions(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return mode.PlanDownloadRef(ctx, options, c.metadataProvider(), opts.Album, chapters)
+}
+
+// downloadOptions 校验下载选项并填充默认值
+func (c *Client) downloadOptions(ctx context.Context, opts DownloadOptions) (mode.DownloadOptions, utils.IntRanges, error) {
+	if opts.UrlTemplate == "" {
+		opts.UrlTemplate = utils.DefaultUrlTemplate
+	}
+	tpl, err := utils.ParseUrlTemplate(opts.UrlTemplate)
+	if err != nil {
+		return mode.DownloadOptions{}, nil, invalidOption("图片地址模板无效", err)
+	}
+	var chapters utils.IntRanges
+	if opts.Chapters != "" {
+		chapters, err = utils.ParseIntRanges(opts.Chapters)
+		if err != nil {
+			return mode.DownloadOptions{}, nil, invalidOption("章节范围无效", err)
+		}
+	}
+	selection, err := parseSelection(opts.Pages, opts.Exclude)
+	if err != nil {
+		return mode.DownloadOptions{}, nil, err
+	}
+	naming, err := utils.ParseNaming(opts.AlbumDir, opts.ChapterDir)
+	if err != nil {
+		return mode.DownloadOptions{}, nil, invalidOption("命名模板无效", err)
+	}
+	plugin, err := c.loadPlugin(ctx, opts.Plugin)
+	if err != nil {
+		return mode.DownloadOptions{}, nil, err
+	}
+
+	if opts.Ext == "" {
+		opts.Ext = utils.DefaultImageExt
+	}
+	if opts.FallbackExts == nil {
+		opts.FallbackExts = utils.DefaultFallbackExts
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 8
+	}
+
+	return mode.DownloadOptions{
+		Cdn:          opts.Cdn,
+		Output:       opts.Output,
+		Client:       c.httpClient,
+		Count:        opts.Count,
+		Concurrency:  opts.Concurrency,
+		UrlTemplate:  tpl,
+		Ext:          opts.Ext,
+		FallbackExts: opts.FallbackExts,
+		Selection:    selection,
+		Plugin:       plugin,
+		Naming:       naming,
+		Events:       opts.Events,
+		Force:        opts.Force,
+	}, chapters, nil
+}
@@ -0,0 +1,50 @@
+package pickit
+
+import (
+	"context"
+	"pickit/internal/mode"
+	"pickit/internal/utils"
+)
+
+// PDFOptions 合成 PDF 的选项
+type PDFOptions struct {
+	Input        string       // 图片文件夹路径，包含子目录时每个子目录作为一个章节
+	Output       string       // PDF 文件路径
+	Password     string       // 密码，为空时不加密
+	Pages        string       // 需要合成的页面，如 1-20,25,-3,ch2:1-5
+	Exclude      string       // 需要跳过的页面，格式同 Pages
+	IncludeFiles []string     // 只读取文件名或相对路径匹配的文件，语法同 path.Match
+	ExcludeFiles []string     // 跳过文件名或相对路径匹配的文件和章节目录
+	Events       EventHandler // 进度事件，可以为 nil
+}
+
+// CreatePDF 将图片合成 PDF，多个章节时按章节添加书签，返回读取目录时跳过的文件
+func (c *Client) CreatePDF(ctx context.Context, opts PDFOptions) ([]SkippedFile, error) {
+	selection, filter, err := pdfOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	return mode.CreatePDF(c.context(ctx), opts.Input, opts.Output, opts.Password, selection, filter, opts.Events)
+}
+
+// PlanPDF 生成合成计划：列出需要合成的图片并检查 PDF 是否会被覆盖，不写入任何文件
+func (c *Client) PlanPDF(opts PDFOptions) (*Plan, error) {
+	selection, filter, err := pdfOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	return mode.PlanPDF(opts.Input, opts.Output, selection, filter)
+}
+
+// pdfOptions 解析页面选择和文件过滤规则
+func pdfOptions(opts PDFOptions) (*utils.PageSelection, *utils.FileFilter, error) {
+	selection, err := parseSelection(opts.Pages, opts.Exclude)
+	if err != nil {
+		return nil, nil, err
+	}
+	filter, err := parseFileFilter(opts.IncludeFiles, opts.ExcludeFiles)
+	if err != nil {
+		return nil, nil, err
+	}
+	return selection, filter, nil
+}
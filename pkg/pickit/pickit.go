@@ -0,0 +1,219 @@
+/*
+Package pickit 提供下载、还原图片和合成 PDF 的公共接口，方便嵌入到其他程序中使用。
+
+	client := pickit.New(pickit.Options{Proxy: "http://127.0.0.1:7890", Logger: logger})
+	ref, _ := pickit.ParseAlbumRef("JM123456")
+	pages, err := client.Download(ctx, pickit.DownloadOptions{Album: ref, Cdn: cdn, Output: "raw", Count: 20})
+
+所有方法都接受 context.Context，取消后尚未开始的页面不再处理；出错时返回错误而不是退出进程，
+参数无效的错误可以用 errors.Is(err, ErrInvalidOption) 判断。单页失败不会导致整个调用失败，
+需要检查返回结果中每一页的 Err。
+*/
+package pickit
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"net/http"
+	"pickit/internal/mode"
+	"pickit/internal/utils"
+	"strings"
+	"time"
+)
+
+// ErrInvalidOption 参数无效，如模板、页面选择或插件名错误
+var ErrInvalidOption = utils.NewError("参数无效")
+
+// 错误类别，可以用 errors.Is 判断，单页和单张图片的错误也会包装这些类别
+var (
+	ErrNotFound          = utils.ErrNotFound          // 资源不存在（404）
+	ErrRateLimited       = utils.ErrRateLimited       // 请求过于频繁（429）
+	ErrUnauthorized      = utils.ErrUnauthorized      // 需要登录或登录已失效（401/403）
+	ErrSessionExpired    = utils.ErrSessionExpired    // 本地保存的登录状态已过期
+	ErrCorruptImage      = utils.ErrCorruptImage      // 图片损坏或格式不支持
+	ErrUnsupportedLayout = utils.ErrUnsupportedLayout // 不支持的目录结构
+	ErrSchemeMismatch    = utils.ErrSchemeMismatch    // 切割方案与图片不匹配
+)
+
+// 可以用 errors.As 获取的错误
+type (
+	HTTPError  = utils.HTTPError  // 服务端返回了非 200 的状态码
+	ImageError = utils.ImageError // 单张图片处理失败
+)
+
+// SetLocale 设置错误和日志消息使用的语言，支持 zh（默认）和 en。语言是全局设置，需要在开始处理之前调用
+func SetLocale(lang string) error {
+	return utils.SetLocale(lang)
+}
+
+// ErrorKind 返回错误类别的名称，如 not_found、rate_limited、corrupt_image，不属于任何类别时返回空字符串
+func ErrorKind(err error) string {
+	return utils.ErrorKind(err)
+}
+
+// 与内部实现共用的类型
+type (
+	AlbumRef      = utils.AlbumRef            // 本子或章节的引用
+	AlbumMeta     = utils.AlbumMeta           // 本子信息
+	ChapterMeta   = utils.ChapterMeta         // 章节信息
+	SearchQuery   = utils.SearchQuery         // 搜索条件
+	SearchResult  = utils.SearchResult        // 搜索结果
+	PageResult    = utils.BatchDownloadResult // 单页的下载结果
+	RestoreResult = utils.DecodeAndSaveResult // 单张图片的还原结果
+	Event         = utils.Event               // 进度和生命周期事件
+	EventHandler  = utils.EventHandler        // 接收事件，批量处理时会在多个协程中同时调用
+	EventFunc     = utils.EventFunc           // 把函数转换为 EventHandler
+	Plan          = mode.Plan                 // 演练模式的执行计划
+	PlanItem      = mode.PlanItem             // 执行计划中的一项操作
+	SkippedFile   = utils.SkippedFile         // 读取图片目录时跳过的文件
+	Session       = utils.Session             // 登录状态，包括 cookie 和 token
+)
+
+// 事件类型
+const (
+	EventJobStarted    = utils.EventJobStarted    // 一批任务开始处理
+	EventItemStarted   = utils.EventItemStarted   // 单项开始处理
+	EventItemProgress  = utils.EventItemProgress  // 单项已传输的字节数发生变化，只有下载会产生
+	EventItemSucceeded = utils.EventItemSucceeded // 单项处理成功
+	EventItemFailed    = utils.EventItemFailed    // 单项处理失败
+	EventJobFinished   = utils.EventJobFinished   // 一批任务处理完成
+)
+
+// 产生事件的阶段
+const (
+	StageDownload = utils.StageDownload // 下载，单项为一页
+	StageRestore  = utils.StageRestore  // 还原，单项为一张图片
+	StagePdf      = utils.StagePdf      // 合成 PDF，单项为一张图片
+)
+
+// ParseAlbumRef 解析车牌号，支持数字、JM 编号和本子/章节链接
+func ParseAlbumRef(s string) (AlbumRef, error) {
+	return utils.ParseAlbumRef(s)
+}
+
+// LoadSession 读取 pickit login 保存的登录状态，path 为空时使用默认路径，文件不存在时返回 nil, nil。
+// 登录状态已过期时同时返回读取到的状态和 ErrSessionExpired
+func LoadSession(path string) (*Session, error) {
+	if path == "" {
+		path = utils.DefaultSessionPath()
+	}
+	return utils.LoadSession(path)
+}
+
+// Options 客户端选项
+type Options struct {
+	HTTPClient *http.Client // 下载图片和请求接口使用的 HTTP 客户端，为 nil 时按 Proxy 创建，超时 15 秒
+	Proxy      string       // 魔法，HTTPClient 不为 nil 时忽略
+	Session    *Session     // 登录状态，为 nil 时不携带。HTTPClient 不为 nil 时使用它的副本，不会修改传入的客户端
+	Logger     *zap.Logger  // 日志器，为 nil 时不输出日志
+	API        string       // 本子信息接口地址，用于自动获取图片数量和章节，以及搜索
+	PluginDir  string       // 插件目录，为空时使用默认目录
+}
+
+// Client 客户端，可以在多个协程中同时使用
+type Client struct {
+	httpClient *http.Client
+	logger     *zap.Logger
+	provider   *utils.HTTPMetadataProvider
+	pluginDir  string
+}
+
+// New 创建客户端
+func New(opts Options) *Client {
+	c := &Client{
+		httpClient: opts.HTTPClient,
+		logger:     opts.Logger,
+		pluginDir:  opts.PluginDir,
+	}
+	if c.logger == nil {
+		c.logger = zap.NewNop()
+	} else {
+		c.logger = utils.LocalizeLogger(c.logger)
+	}
+	if c.httpClient == nil {
+		c.httpClient = utils.NewHTTPClient(opts.Proxy, 15*time.Second)
+	} else if opts.Session != nil {
+		client := *c.httpClient
+		c.httpClient = &client
+	}
+	if opts.Session != nil {
+		if err := opts.Session.Apply(c.httpClient); err != nil {
+			c.logger.Warn("加载登录状态失败", utils.Err(err))
+		}
+	}
+	if c.pluginDir == "" {
+		c.pluginDir = utils.DefaultPluginDir()
+	}
+	if opts.API != "" {
+		c.provider = &utils.HTTPMetadataProvider{
+			BaseUrl: strings.TrimSuffix(opts.API, "/"),
+			Client:  c.httpClient,
+		}
+	}
+	return c
+}
+
+// Album 获取本子信息，需要配置 API
+func (c *Client) Album(ctx context.Context, id int) (*AlbumMeta, error) {
+	if c.provider == nil {
+		return nil, utils.Errorf("%w: 没有配置本子信息接口", ErrInvalidOption)
+	}
+	return c.provider.Album(c.context(ctx), id)
+}
+
+// Search 搜索本子，需要配置 API
+func (c *Client) Search(ctx context.Context, query SearchQuery) (*SearchResult, error) {
+	if c.provider == nil {
+		return nil, utils.Errorf("%w: 没有配置本子信息接口", ErrInvalidOption)
+	}
+	return c.provider.Search(c.context(ctx), query)
+}
+
+// context 返回携带客户端日志器的 context
+func (c *Client) context(ctx context.Context) context.Context {
+	return utils.WithLogger(ctx, c.logger)
+}
+
+// metadataProvider 本子信息来源，没有配置 API 时返回 nil
+func (c *Client) metadataProvider() utils.MetadataProvider {
+	if c.provider == nil {
+		return nil
+	}
+	return c.provider
+}
+
+// loadPlugin 按名称加载插件，name 为空时返回 nil
+func (c *Client) loadPlugin(ctx context.Context, name string) (*utils.Plugin, error) {
+	if name == "" {
+		return nil, nil
+	}
+	plugin, err := utils.LoadPlugin(ctx, c.pluginDir, name)
+	if err != nil {
+		return nil, invalidOption("加载插件失败", err)
+	}
+	return plugin, nil
+}
+
+// parseSelection 解析页面选择，都为空时返回 nil
+func parseSelection(pages, exclude string) (*utils.PageSelection, error) {
+	sel, err := utils.ParsePageSelection(pages, exclude)
+	if err != nil {
+		return nil, invalidOption("页面选择无效", err)
+	}
+	return sel, nil
+}
+
+// parseFileFilter 解析读取图片目录时的文件过滤规则
+func parseFileFilter(include, exclude []string) (*utils.FileFilter, error) {
+	filter, err := utils.NewFileFilter(include, exclude)
+	if err != nil {
+		return nil, invalidOption("文件过滤规则无效", err)
+	}
+	return filter, nil
+}
+
+// invalidOption 包装参数错误，使其可以用 errors.Is(err, ErrInvalidOption) 判断
+func invalidOption(msg string, err error) error {
+	return fmt.Errorf("%w: %s: %w", ErrInvalidOption, utils.T(msg), err)
+}
@@ -0,0 +1,69 @@
+package pickit
+
+import (
+	"context"
+	"pickit/internal/mode"
+	"pickit/internal/utils"
+)
+
+// RestoreOptions 还原选项，除 Input、Output 和 Aid 外都可以为零值
+type RestoreOptions struct {
+	Input        string       // 需要还原的图片文件夹路径，只支持单层目录
+	Output       string       // 还原后的图片输出文件夹路径
+	Aid          int          // 车牌号，多章节本子为章节编号
+	Concurrency  int          // 并发数，为 0 时使用 8
+	Pages        string       // 需要还原的页面，如 1-20,25,-3
+	Exclude      string       // 需要跳过的页面，格式同 Pages
+	IncludeFiles []string     // 只读取文件名或相对路径匹配的文件，语法同 path.Match
+	ExcludeFiles []string     // 跳过文件名或相对路径匹配的文件
+	PageName     string       // 输出文件名模板，为空时使用 {name}.jpeg，{ext} 固定为 jpeg
+	Plugin       string       // 提供切割方案的插件名
+	Events       EventHandler // 进度事件，可以为 nil
+	Force        bool         // 重新还原清单中已完成的图片，默认跳过
+}
+
+// Restore 还原图片，返回每张图片的处理结果（按文件顺序排列）和读取目录时跳过的文件。
+// 参数无效或读取目录失败时返回错误，单张图片还原失败记录在对应结果的 Err 中
+func (c *Client) Restore(ctx context.Context, opts RestoreOptions) ([]RestoreResult, []SkippedFile, error) {
+	ctx = c.context(ctx)
+
+	selection, filter, pageName, err := restoreOptions(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	plugin, err := c.loadPlugin(ctx, opts.Plugin)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 8
+	}
+
+	return mode.RestoreImages(ctx, opts.Input, opts.Output, opts.Aid, opts.Concurrency, plugin, selection, filter, pageName, opts.Events, opts.Force)
+}
+
+// PlanRestore 生成还原计划：列出每张图片的输出路径并检查会被覆盖的文件，不写入任何文件
+func (c *Client) PlanRestore(opts RestoreOptions) (*Plan, error) {
+	selection, filter, pageName, err := restoreOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	return mode.PlanRestore(opts.Input, opts.Output, opts.Aid, selection, filter, pageName)
+}
+
+// restoreOptions 解析页面选择、文件过滤规则和命名模板
+func restoreOptions(opts RestoreOptions) (*utils.PageSelection, *utils.FileFilter, *utils.NameTemplate, error) {
+	selection, err := parseSelection(opts.Pages, opts.Exclude)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	filter, err := parseFileFilter(opts.IncludeFiles, opts.ExcludeFiles)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	pageName, err := utils.ParsePageNameTemplate(opts.PageName)
+	if err != nil {
+		return nil, nil, nil, invalidOption("命名模板无效", err)
+	}
+	return selection, filter, pageName, nil
+}